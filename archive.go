@@ -0,0 +1,333 @@
+package mcapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrUnsafeArchivePath is returned by UploadArchive when an entry in the archive has an
+// absolute path or a path that would place it outside the target directory, such as
+// ../../etc/passwd. Nothing is uploaded when an archive contains such an entry.
+var ErrUnsafeArchivePath = errors.New("mcapi: unsafe path in archive")
+
+// ArchiveExtraction decides where an archive uploaded with UploadArchiveWithOptions is
+// unpacked.
+type ArchiveExtraction int
+
+const (
+	// ExtractOnClient reads the files out of the archive locally and uploads each one.
+	// It works with every server, and lets UploadOptions skip unchanged files.
+	ExtractOnClient ArchiveExtraction = iota
+
+	// ExtractOnServer uploads the archive as a single file and asks the server to unpack
+	// it. This is much faster for archives of many small files, but requires a server that
+	// supports unpacking. The archive is deleted from the project once it's unpacked.
+	ExtractOnServer
+)
+
+// ArchiveOptions control uploads made with UploadArchiveWithOptions.
+type ArchiveOptions struct {
+	Extraction ArchiveExtraction
+
+	// Upload is applied to each file when extracting on the client.
+	Upload UploadOptions
+}
+
+// unpackRequest is the body for the call that unpacks an uploaded archive.
+type unpackRequest struct {
+	ProjectID   int `json:"project_id"`
+	DirectoryID int `json:"directory_id"`
+}
+
+// UploadArchive uploads the contents of a zip, tar or tar.gz archive into projectPath,
+// keeping the relative paths of the files in the archive. The files are extracted on the
+// client. See UploadArchiveWithOptions to have the server extract them instead.
+func (c *Client) UploadArchive(projectID int, archivePath, projectPath string) ([]UploadResult, error) {
+	return c.UploadArchiveWithOptions(projectID, archivePath, projectPath, ArchiveOptions{})
+}
+
+// UploadArchiveWithOptions uploads the contents of a zip, tar or tar.gz archive into
+// projectPath, keeping the relative paths of the files in the archive. The archive's entries
+// are checked before anything is uploaded; if any would land outside projectPath an error
+// matching ErrUnsafeArchivePath is returned. Links and other special entries are skipped.
+//
+// When extracting on the client, the results describe each file in the archive. When
+// extracting on the server, they describe the files the server created.
+func (c *Client) UploadArchiveWithOptions(projectID int, archivePath, projectPath string, opts ArchiveOptions) ([]UploadResult, error) {
+	op := c.startOperation("UploadArchive", projectIDAttr(projectID))
+	if projectPath == "" {
+		projectPath = "/"
+	}
+
+	// Check every entry first, so that a bad archive doesn't leave half its files behind.
+	err := walkArchive(archivePath, func(name string, isDir bool, r io.Reader) error {
+		return nil
+	})
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	var results []UploadResult
+	if opts.Extraction == ExtractOnServer {
+		results, err = op.client().unpackOnServer(projectID, archivePath, projectPath)
+	} else {
+		results, err = op.client().unpackOnClient(projectID, archivePath, projectPath, opts.Upload)
+	}
+
+	return results, op.end(err)
+}
+
+// unpackOnClient extracts each file in the archive to a temporary directory and uploads it,
+// one at a time, so only a single file from the archive is on disk at once.
+func (c *Client) unpackOnClient(projectID int, archivePath, projectPath string, opts UploadOptions) ([]UploadResult, error) {
+	tmpDir, err := os.MkdirTemp("", "mcapi-archive-")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	u := newUploader(c, projectID, opts)
+	var results []UploadResult
+	err = walkArchive(archivePath, func(name string, isDir bool, r io.Reader) error {
+		if isDir {
+			_, err := u.directoryID(path.Join(projectPath, name))
+			return err
+		}
+
+		localPath := filepath.Join(tmpDir, path.Base(name))
+		if err := writeLocalFile(localPath, r); err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = os.Remove(localPath)
+		}()
+
+		result, err := u.upload(localPath, path.Join(projectPath, path.Dir(name)))
+		if err != nil {
+			return fmt.Errorf("unable to upload %s from archive: %w", name, err)
+		}
+
+		result.LocalPath = name
+		results = append(results, *result)
+		return nil
+	})
+
+	return results, err
+}
+
+// unpackOnServer uploads the archive into projectPath and has the server unpack it there.
+func (c *Client) unpackOnServer(projectID int, archivePath, projectPath string) ([]UploadResult, error) {
+	archive, err := c.UploadFileTo(projectID, archivePath, projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := do[[]mcmodel.File](c, apiRequest{
+		op:     "UnpackArchive",
+		method: http.MethodPost,
+		path:   apiPath("/files/%d/unpack", archive.ID),
+		body:   unpackRequest{ProjectID: projectID, DirectoryID: archive.DirectoryID},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+	if err != nil {
+		// Don't leave the archive behind as a stray file in the project.
+		if deleteErr := c.DeleteFile(projectID, IDRef(archive.ID)); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("uploaded archive couldn't be deleted: %w", deleteErr))
+		}
+		return nil, err
+	}
+
+	if err := c.DeleteFile(projectID, IDRef(archive.ID)); err != nil {
+		return nil, fmt.Errorf("archive unpacked but couldn't be deleted: %w", err)
+	}
+
+	results := make([]UploadResult, 0, len(*files))
+	for i := range *files {
+		f := &(*files)[i]
+		results = append(results, UploadResult{ProjectPath: f.Path, File: f})
+	}
+
+	return results, nil
+}
+
+// writeLocalFile writes the contents of r to a new file at filePath.
+func writeLocalFile(filePath string, r io.Reader) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// archiveEntryFunc is called by walkArchive for each file and directory in an archive. name
+// is the entry's cleaned, relative, slash separated path. r is nil for directories.
+type archiveEntryFunc func(name string, isDir bool, r io.Reader) error
+
+// walkArchive calls fn for each regular file and directory in the archive at archivePath. The
+// format is chosen by the file's extension: .zip, .tar, or .tar.gz/.tgz. Entries with unsafe
+// paths stop the walk with ErrUnsafeArchivePath.
+func walkArchive(archivePath string, fn archiveEntryFunc) error {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return walkZip(archivePath, fn)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return walkTar(archivePath, true, fn)
+	case strings.HasSuffix(lower, ".tar"):
+		return walkTar(archivePath, false, fn)
+	default:
+		return fmt.Errorf("unsupported archive type: %s", filepath.Base(archivePath))
+	}
+}
+
+// walkZip walks the entries of a zip archive.
+func walkZip(archivePath string, fn archiveEntryFunc) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	for _, entry := range zr.File {
+		name, err := safeArchivePath(entry.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := entry.Mode()
+		switch {
+		case name == "":
+			continue
+		case mode.IsDir():
+			err = fn(name, true, nil)
+		case mode.IsRegular():
+			err = walkZipFile(entry, name, fn)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkZipFile opens a single file in a zip archive and passes it to fn.
+func walkZipFile(entry *zip.File, name string, fn archiveEntryFunc) error {
+	r, err := entry.Open()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	return fn(name, false, r)
+}
+
+// walkTar walks the entries of a tar archive, decompressing it first if gzipped is true.
+func walkTar(archivePath string, gzipped bool, fn archiveEntryFunc) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = gz.Close()
+		}()
+
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name, err := safeArchivePath(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case name == "":
+			continue
+		case hdr.Typeflag == tar.TypeDir:
+			err = fn(name, true, nil)
+		case hdr.Typeflag == tar.TypeReg:
+			err = fn(name, false, tr)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// safeArchivePath cleans an archive entry's name into a relative, slash separated path. It
+// returns ErrUnsafeArchivePath for absolute paths and paths that climb out of the archive's
+// root. The root directory itself, such as "./", is returned as "".
+func safeArchivePath(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(slashed) || filepath.VolumeName(name) != "" || (len(slashed) > 1 && slashed[1] == ':') {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+
+	cleaned := path.Clean(slashed)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+
+	if cleaned == "." {
+		return "", nil
+	}
+
+	return cleaned, nil
+}
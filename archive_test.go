@@ -0,0 +1,216 @@
+package mcapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// writeTestZip writes a zip archive containing files, a map of entry name to contents.
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(contents))
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+// handleArchiveDirs serves directory creation and uploads for any directory, recording the
+// project path each file was uploaded to.
+func handleArchiveDirs(srv *fakeServer) func() []string {
+	var (
+		mu       sync.Mutex
+		dirs     = map[int]string{}
+		uploaded []string
+	)
+
+	srv.handleFunc(http.MethodPost, "/directories/by-path", func(w http.ResponseWriter, r *http.Request) {
+		var req projectPathRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		id := len(dirs) + 100
+		dirs[id] = req.Path
+		mu.Unlock()
+		writeJSON(w, fmt.Sprintf(`{"data": {"id": %d, "path": %q, "mime_type": "directory"}}`, id, req.Path))
+	})
+
+	for id := 100; id < 110; id++ {
+		id := id
+		srv.handleFunc(http.MethodPost, fmt.Sprintf("/projects/1/files/%d/upload", id), func(w http.ResponseWriter, r *http.Request) {
+			_, header, err := r.FormFile("files[]")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			uploaded = append(uploaded, dirs[id]+"/"+header.Filename)
+			mu.Unlock()
+			writeJSON(w, `{"data": [{"id": 1, "name": "`+header.Filename+`"}]}`)
+		})
+	}
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sort.Strings(uploaded)
+		return uploaded
+	}
+}
+
+func TestClient_UploadArchiveZip(t *testing.T) {
+	archivePath := writeTestZip(t, map[string]string{
+		"scan/a.tif":        "a",
+		"scan/b.tif":        "b",
+		"scan/meta/run.txt": "run",
+		"./readme.txt":      "readme",
+	})
+
+	srv := newFakeServer(t)
+	uploaded := handleArchiveDirs(srv)
+
+	results, err := srv.client().UploadArchive(1, archivePath, "/instrument")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %+v", results)
+	}
+
+	expected := []string{"/instrument/readme.txt", "/instrument/scan/a.tif", "/instrument/scan/b.tif", "/instrument/scan/meta/run.txt"}
+	got := uploaded()
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected uploads %v, got %v", expected, got)
+	}
+
+	dirRequests := 0
+	for _, req := range srv.received() {
+		if req.Path == "/directories/by-path" {
+			dirRequests++
+		}
+	}
+	if dirRequests != 3 {
+		t.Errorf("expected each directory to be created once, got %d requests", dirRequests)
+	}
+}
+
+func TestClient_UploadArchiveRejectsTraversal(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"ok.txt", "data/../../evil.txt"} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 1, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("x"))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+
+	srv := newFakeServer(t)
+	_, err = srv.client().UploadArchive(1, archivePath, "/")
+	if !errors.Is(err, ErrUnsafeArchivePath) {
+		t.Fatalf("expected ErrUnsafeArchivePath, got %v", err)
+	}
+
+	if n := len(srv.received()); n != 0 {
+		t.Errorf("nothing should be uploaded from an unsafe archive, got %d requests", n)
+	}
+}
+
+func TestClient_UploadArchiveOnServer(t *testing.T) {
+	archivePath := writeTestZip(t, map[string]string{"a.txt": "a"})
+
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/directories/by-path", `{"data": {"id": 2, "mime_type": "directory"}}`)
+	srv.handle(http.MethodPost, "/projects/1/files/2/upload", `{"data": [{"id": 50, "directory_id": 2}]}`)
+	srv.handle(http.MethodPost, "/files/50/unpack", `{"data": [{"id": 51, "path": "/raw/a.txt"}]}`)
+	srv.handle(http.MethodDelete, "/files/50", `{}`)
+
+	results, err := srv.client().UploadArchiveWithOptions(1, archivePath, "/raw", ArchiveOptions{Extraction: ExtractOnServer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].ProjectPath != "/raw/a.txt" || results[0].File.ID != 51 {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	received := srv.received()
+	if last := received[len(received)-1]; last.Method != http.MethodDelete {
+		t.Errorf("expected the archive to be deleted after unpacking, last request was %s %s", last.Method, last.Path)
+	}
+}
+
+func TestClient_UploadArchiveOnServerDeletesArchiveWhenUnpackFails(t *testing.T) {
+	archivePath := writeTestZip(t, map[string]string{"a.txt": "a"})
+
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/directories/by-path", `{"data": {"id": 2, "mime_type": "directory"}}`)
+	srv.handle(http.MethodPost, "/projects/1/files/2/upload", `{"data": [{"id": 50, "directory_id": 2}]}`)
+	srv.handle(http.MethodDelete, "/files/50", `{}`)
+
+	// The fake server has no unpack route, so unpacking fails with a 404.
+	_, err := srv.client().UploadArchiveWithOptions(1, archivePath, "/raw", ArchiveOptions{Extraction: ExtractOnServer})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the unpack error, got %v", err)
+	}
+
+	received := srv.received()
+	if last := received[len(received)-1]; last.Method != http.MethodDelete || last.Path != "/files/50" {
+		t.Errorf("expected the archive to be deleted after the failed unpack, last request was %s %s", last.Method, last.Path)
+	}
+}
+
+func TestSafeArchivePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		unsafe   bool
+	}{
+		{"a/b.txt", "a/b.txt", false},
+		{"./a/./b.txt", "a/b.txt", false},
+		{"./", "", false},
+		{"a/../b.txt", "b.txt", false},
+		{"../b.txt", "", true},
+		{"a/../../b.txt", "", true},
+		{"/etc/passwd", "", true},
+		{`..\windows\b.txt`, "", true},
+		{`C:\b.txt`, "", true},
+	}
+
+	for _, test := range tests {
+		got, err := safeArchivePath(test.name)
+		if test.unsafe != errors.Is(err, ErrUnsafeArchivePath) || got != test.expected {
+			t.Errorf("safeArchivePath(%q) = %q, %v", test.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package mcapi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// errPartChecksum is returned when the server's checksum for a part doesn't match the data sent.
+var errPartChecksum = errors.New("part checksum mismatch")
+
+// ChunkedUpload configures how large files are uploaded. Files bigger than Threshold are
+// split into parts that are uploaded separately, in parallel, and then joined by the
+// server. This keeps each request under the body size limits of proxies between the
+// client and the server, and means a failed part can be retried without starting over.
+// The zero value uses the defaults described for each field.
+type ChunkedUpload struct {
+	// Threshold is the file size, in bytes, above which files are uploaded in parts. It
+	// defaults to 1 GiB. Set it to a negative number to always upload in one request.
+	Threshold int64
+
+	// PartSize is the size, in bytes, of each part. It defaults to 64 MiB. The server may
+	// choose a different part size, in which case the server's size is used.
+	PartSize int64
+
+	// Concurrency is the number of parts uploaded at once. It defaults to 4.
+	Concurrency int
+
+	// PartRetries is the number of times a part is retried after a network error or a
+	// server error before the upload fails. It defaults to 3.
+	PartRetries int
+}
+
+const (
+	defaultChunkThreshold   = 1 << 30
+	defaultChunkPartSize    = 64 << 20
+	defaultChunkConcurrency = 4
+	defaultChunkPartRetries = 3
+)
+
+// withDefaults returns a copy of cu with the zero fields set to their defaults.
+func (cu ChunkedUpload) withDefaults() ChunkedUpload {
+	if cu.Threshold == 0 {
+		cu.Threshold = defaultChunkThreshold
+	}
+
+	if cu.PartSize <= 0 {
+		cu.PartSize = defaultChunkPartSize
+	}
+
+	if cu.Concurrency <= 0 {
+		cu.Concurrency = defaultChunkConcurrency
+	}
+
+	if cu.PartRetries < 0 {
+		cu.PartRetries = 0
+	} else if cu.PartRetries == 0 {
+		cu.PartRetries = defaultChunkPartRetries
+	}
+
+	return cu
+}
+
+// useChunks returns true if a file of the given size should be uploaded in parts.
+func (cu ChunkedUpload) useChunks(size int64) bool {
+	return cu.Threshold >= 0 && size > cu.Threshold
+}
+
+// initChunkedUploadRequest starts a chunked upload of a file into a directory.
+type initChunkedUploadRequest struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	PartSize int64  `json:"part_size"`
+}
+
+// chunkedUploadSession is the server's response to starting a chunked upload.
+type chunkedUploadSession struct {
+	ID       string `json:"id"`
+	PartSize int64  `json:"part_size"`
+}
+
+// uploadedPart is a part the server has received. Parts are numbered from 1.
+type uploadedPart struct {
+	PartNumber int    `json:"part_number"`
+	Checksum   string `json:"checksum"`
+}
+
+// completeChunkedUploadRequest asks the server to join the parts into the file.
+type completeChunkedUploadRequest struct {
+	Parts []uploadedPart `json:"parts"`
+}
+
+// uploadChunked uploads f, which is size bytes long, into the directory in parts as part of
+// op. If any part fails, the upload is aborted so the server can discard the parts it has.
+func (op *operation) uploadChunked(projectID, directoryID int, f *os.File, fileName string, size int64) (*mcmodel.File, error) {
+	cu := op.c.chunked
+	var session chunkedUploadSession
+	err := op.send(apiRequest{
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/files/%d/uploads", projectID, directoryID),
+		body:   initChunkedUploadRequest{Name: fileName, Size: size, PartSize: cu.PartSize},
+	}, &DataWrapper{&session})
+	if err != nil {
+		return nil, err
+	}
+
+	if session.PartSize <= 0 {
+		session.PartSize = cu.PartSize
+	}
+
+	parts, err := op.uploadParts(projectID, session, f, size)
+	if err != nil {
+		op.abortChunkedUpload(projectID, session.ID)
+		return nil, err
+	}
+
+	var file mcmodel.File
+	err = op.send(apiRequest{
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/uploads/%s/complete", projectID, session.ID),
+		body:   completeChunkedUploadRequest{Parts: parts},
+	}, &DataWrapper{&file})
+	if err != nil {
+		op.abortChunkedUpload(projectID, session.ID)
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// uploadParts uploads the parts of f using up to Concurrency goroutines. The first part to
+// fail cancels the others.
+func (op *operation) uploadParts(projectID int, session chunkedUploadSession, f *os.File, size int64) ([]uploadedPart, error) {
+	numParts := int((size + session.PartSize - 1) / session.PartSize)
+	ctx, cancel := context.WithCancel(op.ctx)
+	defer cancel()
+
+	// Each part is a separate operation, so that it gets its own span and log entry.
+	pc := op.c.WithContext(ctx)
+
+	partNumbers := make(chan int)
+	go func() {
+		defer close(partNumbers)
+		for n := 1; n <= numParts; n++ {
+			select {
+			case partNumbers <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		parts    = make([]uploadedPart, 0, numParts)
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < min(op.c.chunked.Concurrency, numParts); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range partNumbers {
+				offset := int64(n-1) * session.PartSize
+				part, err := pc.uploadPart(projectID, session.ID, n, io.NewSectionReader(f, offset, min(session.PartSize, size-offset)))
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("part %d of %d: %w", n, numParts, err)
+						cancel()
+					}
+				} else {
+					parts = append(parts, *part)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	op.addUploadedBytes(size)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+// uploadPart uploads a single part, retrying it up to PartRetries times.
+func (c *Client) uploadPart(projectID int, sessionID string, partNumber int, part *io.SectionReader) (*uploadedPart, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, part); err != nil {
+		return nil, err
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+
+	var (
+		result *uploadedPart
+		err    error
+	)
+
+	for attempt := 0; attempt <= c.chunked.PartRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(c.context(), partRetryDelay(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		result, err = do[uploadedPart](c, apiRequest{
+			op:     "UploadFilePart",
+			method: http.MethodPut,
+			path:   apiPath("/projects/%d/uploads/%s/parts/%d", projectID, sessionID, partNumber),
+			upload: true,
+			attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+			prepare: func(r *resty.Request) {
+				r.SetHeader("Content-Type", "application/octet-stream").
+					SetBody(io.NewSectionReader(part, 0, part.Size()))
+			},
+		})
+		if err == nil && result.Checksum != "" && result.Checksum != checksum {
+			// The part was corrupted on the way, so send it again.
+			err = fmt.Errorf("%w: server checksum %s, expected %s", errPartChecksum, result.Checksum, checksum)
+		}
+
+		if err == nil || !retryablePartError(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result.PartNumber == 0 {
+		result.PartNumber = partNumber
+	}
+
+	if result.Checksum == "" {
+		result.Checksum = checksum
+	}
+
+	return result, nil
+}
+
+// abortChunkedUpload tells the server to discard an unfinished upload. It's best effort; the
+// server also expires uploads that are never completed.
+func (op *operation) abortChunkedUpload(projectID int, sessionID string) {
+	// Use a fresh context, since op.ctx may be the reason the upload failed.
+	ctx := context.WithoutCancel(op.ctx)
+	_ = op.c.WithContext(ctx).send(apiRequest{
+		op:     "AbortUpload",
+		method: http.MethodDelete,
+		path:   apiPath("/projects/%d/uploads/%s", projectID, sessionID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	}, nil)
+}
+
+// retryablePartError returns true if a failed part upload is worth trying again. Server
+// errors and network errors are retried. Other errors from the API, such as a 404 for an
+// upload that has expired, are not.
+func retryablePartError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+// partRetryDelay is how long to wait before the given retry attempt of a part.
+func partRetryDelay(attempt int) time.Duration {
+	return time.Duration(attempt*attempt) * 100 * time.Millisecond
+}
+
+// sleepContext waits for d, returning early with the context's error if ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package mcapi
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_UploadFileChunked(t *testing.T) {
+	contents := []byte("a large tomography volume")
+	local := filepath.Join(t.TempDir(), "volume.raw")
+	if err := os.WriteFile(local, contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	uploads := srv.handleChunkedUploads(2, 4, map[int]int{3: http.StatusServiceUnavailable})
+	c := NewClient(&ClientArgs{
+		BaseURL:       srv.URL,
+		ChunkedUpload: ChunkedUpload{Threshold: 10, PartSize: 4, Concurrency: 3},
+	})
+
+	file, err := c.UploadFile(1, 2, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.ID != 99 || file.Size != uint64(len(contents)) {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	if !bytes.Equal(uploads.completed, contents) {
+		t.Errorf("server assembled %q, expected %q", uploads.completed, contents)
+	}
+
+	if uploads.attempts[3] != 2 {
+		t.Errorf("expected part 3 to be retried once, got %d attempts", uploads.attempts[3])
+	}
+
+	for _, req := range srv.received() {
+		if req.Path == "/projects/1/files/2/upload" {
+			t.Error("file over the threshold should not be uploaded in one request")
+		}
+	}
+}
+
+func TestClient_UploadFileChunkedAborts(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "volume.raw")
+	if err := os.WriteFile(local, bytes.Repeat([]byte("x"), 20), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	uploads := srv.handleChunkedUploads(2, 4, map[int]int{2: http.StatusRequestEntityTooLarge})
+	c := NewClient(&ClientArgs{
+		BaseURL:       srv.URL,
+		ChunkedUpload: ChunkedUpload{Threshold: 10, PartSize: 4},
+	})
+
+	if _, err := c.UploadFile(1, 2, local); err == nil {
+		t.Fatal("expected error when a part is rejected")
+	}
+
+	if uploads.attempts[2] != 1 {
+		t.Errorf("a rejected part should not be retried, got %d attempts", uploads.attempts[2])
+	}
+
+	if !uploads.aborted || uploads.completed != nil {
+		t.Error("expected the failed upload to be aborted")
+	}
+}
+
+func TestClient_UploadFileBelowThreshold(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "small.txt")
+	if err := os.WriteFile(local, []byte("small"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/projects/1/files/2/upload", `{"data": [{"id": 5}]}`)
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, ChunkedUpload: ChunkedUpload{Threshold: 10}})
+
+	if file, err := c.UploadFile(1, 2, local); err != nil || file.ID != 5 {
+		t.Errorf("UploadFile returned %+v, %v", file, err)
+	}
+}
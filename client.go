@@ -1,14 +1,22 @@
 package mcapi
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DataWrapper wraps json responses that have a data key before getting to the data, eg
@@ -17,6 +25,16 @@ type DataWrapper struct {
 	Data any `json:"data"`
 }
 
+// ErrUnauthorized matches API errors caused by a missing, invalid or expired API key.
+var ErrUnauthorized = errors.New("mcapi: unauthorized")
+
+// ErrForbidden matches API errors for calls the user isn't allowed to make, such as adding
+// members to a project they don't own. The API key itself is valid.
+var ErrForbidden = errors.New("mcapi: forbidden")
+
+// ErrNotFound matches API errors for a project, file or other item that doesn't exist.
+var ErrNotFound = errors.New("mcapi: not found")
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -32,6 +50,21 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("api error: %d %s", e.StatusCode, e.Status)
 }
 
+// Is reports whether the APIError matches target, which lets callers check for
+// ErrUnauthorized, ErrForbidden and ErrNotFound with errors.Is.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	default:
+		return false
+	}
+}
+
 // NewAPIError creates an instance of APIError from a resty.Response. It extracts the
 // StatusCode and Status from the response.
 func NewAPIError(resp *resty.Response) *APIError {
@@ -54,42 +87,105 @@ var tlsConfig = tls.Config{InsecureSkipVerify: true}
 
 // Client is REST client for the Materials Commons API.
 type Client struct {
-	APIKey  string
-	BaseURL string
-	rClient *resty.Client
+	APIKey      string
+	BaseURL     string
+	rClient     *resty.Client
+	logger      *atomic.Pointer[slog.Logger]
+	debugLogger *atomic.Pointer[slog.Logger] // the stdout logger installed by SetDebug, if any
+	logLevel    *slog.LevelVar
+	telemetry   *telemetry
+	hooks       *hookChain
+	chunked     ChunkedUpload
+	ctx         context.Context
 }
 
 // ClientArgs are the arguments when creating the client. You specify the URL to the server and the
 // API Key for the user. If BaseURL is blank then it defaults to https://materialscommons.org/api.
+// If Logger is set then a record is written to it for each request, filtered by the level in the
+// mclogging config setting. TracerProvider and MeterProvider turn on OpenTelemetry tracing and
+// metrics for the client's calls. RateLimit limits the calls made for metadata, and UploadRateLimit
+// limits file uploads, so that each has a separate budget. MaxRetries is the number of times a
+// call is retried when the server is unavailable or asks the client to slow down. ChunkedUpload
+// controls when and how large files are uploaded in parts.
 type ClientArgs struct {
-	APIKey  string
-	BaseURL string
+	APIKey          string
+	BaseURL         string
+	Logger          *slog.Logger
+	TracerProvider  trace.TracerProvider
+	MeterProvider   metric.MeterProvider
+	RateLimit       RateLimit
+	UploadRateLimit RateLimit
+	MaxRetries      int
+	ChunkedUpload   ChunkedUpload
 }
 
 // NewClient creates a new client, sets the Accept and Content-Type headers to
 // "application/json", and sets the Authorization header to the token. It
 // does a small amount of cleaning on the BaseURL by removing the trailing
 // slashes in the baseURL so the API can construct paths easier.
+//
+// Hooks registered with OnBeforeRequest and OnAfterResponse are run for
+// every call the client makes.
 func NewClient(args *ClientArgs) *Client {
-	c := resty.New().
+	rc := resty.New().
 		SetTLSClientConfig(&tlsConfig).
 		SetHeader("Accept", "application/json").
 		SetHeader("Content-Type", "application/json").
 		SetAuthToken(args.APIKey)
+	rc.SetTransport(newLimitedTransport(rc.GetClient().Transport, args.RateLimit, args.UploadRateLimit))
+
+	if args.MaxRetries > 0 {
+		rc.SetRetryCount(args.MaxRetries).
+			SetRetryResetReaders(true).
+			AddRetryCondition(shouldRetry).
+			AddRetryHook(closeRetriedBody)
+	}
 
 	baseURL := "https://materialscommons.org/api"
 	if args.BaseURL != "" {
 		baseURL = strings.TrimSuffix(args.BaseURL, "/")
 	}
-	return &Client{
-		BaseURL: baseURL,
-		APIKey:  args.APIKey,
-		rClient: c,
+
+	c := &Client{
+		BaseURL:     baseURL,
+		APIKey:      args.APIKey,
+		rClient:     rc,
+		logger:      &atomic.Pointer[slog.Logger]{},
+		debugLogger: &atomic.Pointer[slog.Logger]{},
+		logLevel:    &slog.LevelVar{},
+		telemetry:   newTelemetry(args.TracerProvider, args.MeterProvider),
+		hooks:       &hookChain{},
+		chunked:     args.ChunkedUpload.withDefaults(),
 	}
+
+	c.SetLogger(args.Logger)
+	c.logLevel.Set(configLogLevel())
+	rc.SetLogger(restyLogger{c})
+	rc.OnBeforeRequest(c.runBeforeHooks).
+		OnSuccess(c.logResponse).
+		OnSuccess(c.runAfterHooksOnSuccess).
+		OnError(c.logError).
+		OnError(c.runAfterHooksOnError)
+
+	return c
 }
 
-func (c *Client) SetDebug(on bool) {
-	c.rClient.SetDebug(on)
+// WithContext returns a copy of the client whose calls use ctx. The copy shares the
+// underlying connection, logger and telemetry with c. Cancelling ctx cancels any
+// in progress calls made through the copy, and spans for those calls are created
+// as children of any span in ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// context returns the context set with WithContext, or context.Background if there is none.
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
 func checkError(resp *resty.Response, err error) error {
@@ -108,124 +204,87 @@ func (c *Client) r() *resty.Request {
 
 // CreateProject creates a new project with the specified parameters in CreateProjectRequest and returns the created project.
 func (c *Client) CreateProject(req CreateProjectRequest) (*mcmodel.Project, error) {
-	proj := &mcmodel.Project{}
-
-	url := c.BaseURL + "/projects"
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{proj}).
-		Post(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-
-	return proj, nil
+	return do[mcmodel.Project](c, apiRequest{
+		op:     "CreateProject",
+		method: http.MethodPost,
+		path:   "/projects",
+		body:   req,
+	})
 }
 
 // GetProject retrieves the project details for the given project ID.
 // It returns a pointer to the Project object and an error, if any.
 func (c *Client) GetProject(id int) (*mcmodel.Project, error) {
-	proj := &mcmodel.Project{}
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d", id)
-	resp, err := c.r().
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{proj}).
-		Get(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-
-	return proj, nil
+	return do[mcmodel.Project](c, apiRequest{
+		op:     "GetProject",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d", id),
+		attrs:  []attribute.KeyValue{projectIDAttr(id)},
+	})
 }
 
 // DeleteProject deletes a project identified by the provided ID.
 // It makes a DELETE request to the API endpoint corresponding to the given project ID.
 // Returns an error if the project could not be deleted or if there is any issue with the request.
 func (c *Client) DeleteProject(id int) error {
-	url := c.BaseURL + fmt.Sprintf("/projects/%d", id)
-	resp, err := c.r().Delete(url)
-	return checkError(resp, err)
+	return c.send(apiRequest{
+		op:     "DeleteProject",
+		method: http.MethodDelete,
+		path:   apiPath("/projects/%d", id),
+		attrs:  []attribute.KeyValue{projectIDAttr(id)},
+	}, nil)
 }
 
 // CreateExperiment creates a new experiment based on the given CreateExperimentRequest.
 func (c *Client) CreateExperiment(request CreateExperimentRequest) (*mcmodel.Experiment, error) {
-	experiment := &mcmodel.Experiment{}
-
-	url := c.BaseURL + "/experiments"
-	resp, err := c.r().
-		SetBody(request).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{experiment}).
-		Post(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return experiment, nil
+	return do[mcmodel.Experiment](c, apiRequest{
+		op:     "CreateExperiment",
+		method: http.MethodPost,
+		path:   "/experiments",
+		body:   request,
+		attrs:  []attribute.KeyValue{projectIDAttr(request.ProjectID)},
+	})
 }
 
 // CreateDataset creates a new dataset within the specified project.
 // It takes a projectID and a CreateOrUpdateDatasetRequest as parameters.
 // It returns a pointer to the created Dataset object or an error, if any occurs.
 func (c *Client) CreateDataset(projectID int, req CreateOrUpdateDatasetRequest) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets", projectID)
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset}).
-		Post(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "CreateDataset",
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/datasets", projectID),
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
 }
 
+// GetDataset retrieves the dataset with the given ID from a project.
 func (c *Client) GetDataset(projectID int, datasetID int) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets/%d", projectID, datasetID)
-	resp, err := c.r().
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset}).
-		Get(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "GetDataset",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/datasets/%d", projectID, datasetID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
 }
 
 // UpdateDataset updates an existing dataset for the given project.
 // Takes in a projectID, datasetID, and a CreateOrUpdateDatasetRequest object.
 // Returns the updated Dataset object or an error if the update fails.
 func (c *Client) UpdateDataset(projectID int, datasetID int, req CreateOrUpdateDatasetRequest) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets/%d", projectID, datasetID)
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset}).
-		Put(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "UpdateDataset",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d", projectID, datasetID),
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
 }
 
 // UpdateDatasetFileSelection updates the file selection criteria for a specified dataset within a project.
 // It includes and excludes specified files and directories based on the DatasetFileSelection object.
 func (c *Client) UpdateDatasetFileSelection(projectID, datasetID int, fileSelection DatasetFileSelection) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-
 	if fileSelection.ExcludeFiles == nil {
 		fileSelection.ExcludeFiles = []string{}
 	}
@@ -242,90 +301,67 @@ func (c *Client) UpdateDatasetFileSelection(projectID, datasetID int, fileSelect
 		fileSelection.IncludeDirs = []string{}
 	}
 
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets/%d/change_file_selection", projectID, datasetID)
-	resp, err := c.r().
-		SetBody(fileSelection).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset}).
-		Put(url)
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "UpdateDatasetFileSelection",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d/change_file_selection", projectID, datasetID),
+		body:   fileSelection,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// projectIDRequest is the body for dataset calls that only need the project ID.
+type projectIDRequest struct {
+	ProjectID int `json:"project_id"`
+}
 
-	if err := checkError(resp, err); err != nil {
-		return nil, err
+// testQuery returns the query parameters that mark a publish or DOI request as a test.
+func testQuery(test bool) map[string]string {
+	if !test {
+		return nil
 	}
-	return dataset, nil
+	return map[string]string{"test": "true"}
 }
 
 // PublishDataset publishes a specified dataset by its datasetID in a particular project identified by projectID.
 // Returns the published Dataset object or an error if the operation fails.
 func (c *Client) PublishDataset(projectID int, datasetID int, publishAsTestDataset bool) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-	var req struct {
-		ProjectID int `json:"project_id"`
-	}
-	req.ProjectID = projectID
-	url := c.BaseURL + fmt.Sprintf("/datasets/%d/publish", datasetID)
-
-	request := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset})
-
-	if publishAsTestDataset {
-		request = request.SetQueryParam("test", "true")
-	}
-
-	resp, err := request.Put(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "PublishDataset",
+		method: http.MethodPut,
+		path:   apiPath("/datasets/%d/publish", datasetID),
+		body:   projectIDRequest{ProjectID: projectID},
+		query:  testQuery(publishAsTestDataset),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
 }
 
 // UnpublishDataset unpublishes a dataset associated with a specified project and dataset ID,
 // returning the updated dataset or an error if the operation fails.
 func (c *Client) UnpublishDataset(projectID int, datasetID int) (*mcmodel.Dataset, error) {
-	dataset := &mcmodel.Dataset{}
-	var req struct {
-		ProjectID int `json:"project_id"`
-	}
-	req.ProjectID = projectID
-
-	url := c.BaseURL + fmt.Sprintf("/datasets/%d/unpublish", datasetID)
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{dataset}).
-		Put(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "UnpublishDataset",
+		method: http.MethodPut,
+		path:   apiPath("/datasets/%d/unpublish", datasetID),
+		body:   projectIDRequest{ProjectID: projectID},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
 }
 
 // CreateActivity creates a new activity based on the provided CreateActivityRequest struct.
 func (c *Client) CreateActivity(req CreateActivityRequest) (*mcmodel.Activity, error) {
-	activity := &mcmodel.Activity{}
-
-	url := c.BaseURL + "/activities"
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{activity}).
-		Post(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return activity, nil
+	return do[mcmodel.Activity](c, apiRequest{
+		op:     "CreateActivity",
+		method: http.MethodPost,
+		path:   "/activities",
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(req.ProjectID)},
+	})
 }
 
 // CreateEntity creates a new entity based on the provided request and returns the created entity or an error.
 // The category in the request must be either 'experimental' or 'computational'. Defaults to 'experimental'.
 func (c *Client) CreateEntity(req CreateEntityRequest) (*mcmodel.Entity, error) {
-	entity := &mcmodel.Entity{}
-
 	if req.Category == "" {
 		req.Category = "experimental"
 	}
@@ -334,99 +370,76 @@ func (c *Client) CreateEntity(req CreateEntityRequest) (*mcmodel.Entity, error)
 		return nil, fmt.Errorf("category must be either 'experimental' or 'computational'")
 	}
 
-	url := c.BaseURL + "/entities"
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{entity}).
-		Post(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return entity, nil
+	return do[mcmodel.Entity](c, apiRequest{
+		op:     "CreateEntity",
+		method: http.MethodPost,
+		path:   "/entities",
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(req.ProjectID)},
+	})
 }
 
 // CreateEntityState creates a new entity state associated with the provided project, entity, and activity IDs.
 func (c *Client) CreateEntityState(projectID, entityID, activityID int, req CreateEntityStateRequest) (*mcmodel.Entity, error) {
-	entity := &mcmodel.Entity{}
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/entities/%d/activities/%d/create-entity-state", projectID, entityID, activityID)
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{entity}).
-		Post(url)
+	return do[mcmodel.Entity](c, apiRequest{
+		op:     "CreateEntityState",
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/entities/%d/activities/%d/create-entity-state", projectID, entityID, activityID),
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+}
 
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return entity, nil
+// projectPathRequest is the body for calls that look up or create a file by its path in a project.
+type projectPathRequest struct {
+	ProjectID int    `json:"project_id"`
+	Path      string `json:"path"`
 }
 
 // GetFileByPath fetches a file from a project given the specified project ID and file path.
 // It returns a pointer to the file and an error if the fetch operation fails.
 func (c *Client) GetFileByPath(projectID int, path string) (*mcmodel.File, error) {
-	file := &mcmodel.File{}
-	req := struct {
-		ProjectID int    `json:"project_id"`
-		Path      string `json:"path"`
-	}{
-		ProjectID: projectID,
-		Path:      path,
-	}
-
-	url := c.BaseURL + "/files/by_path"
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{file}).
-		Post(url)
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return file, nil
+	return do[mcmodel.File](c, apiRequest{
+		op:       "GetFileByPath",
+		method:   http.MethodPost,
+		path:     "/files/by_path",
+		body:     projectPathRequest{ProjectID: projectID, Path: path},
+		attrs:    []attribute.KeyValue{projectIDAttr(projectID)},
+		readOnly: true,
+	})
 }
 
 // CreateDirectoryByPath creates a directory at the specified path within the given project. If the
 // directory already exists, it returns the existing directory. It takes a project ID and a path as
 // parameters and returns the created directory or an error.
 func (c *Client) CreateDirectoryByPath(projectID int, path string) (*mcmodel.File, error) {
-	file := &mcmodel.File{}
-	req := struct {
-		ProjectID int    `json:"project_id"`
-		Path      string `json:"path"`
-	}{
-		ProjectID: projectID,
-		Path:      path,
-	}
-
-	resp, err := c.r().
-		SetBody(req).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{file}).
-		Post(c.BaseURL + "/directories/by-path")
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return file, nil
+	return do[mcmodel.File](c, apiRequest{
+		op:     "CreateDirectoryByPath",
+		method: http.MethodPost,
+		path:   "/directories/by-path",
+		body:   projectPathRequest{ProjectID: projectID, Path: path},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
 }
 
 // UploadFileTo uploads a file to a specified project directory path. If the project directory path is
 // blank then it uploads the file to the project root. If the directory does not exist, it creates it.
 func (c *Client) UploadFileTo(projectID int, filePath string, projectPath string) (*mcmodel.File, error) {
+	op := c.startOperation("UploadFileTo", projectIDAttr(projectID))
 	if projectPath == "" {
 		projectPath = "/"
 	}
-	dir, err := c.CreateDirectoryByPath(projectID, projectPath)
+	dir, err := op.client().CreateDirectoryByPath(projectID, projectPath)
 	if err != nil {
-		return nil, err
+		return nil, op.end(err)
 	}
 
-	return c.UploadFile(projectID, dir.ID, filePath)
+	file, err := op.client().UploadFile(projectID, dir.ID, filePath)
+	return file, op.end(err)
 }
 
-// UploadFile uploads a file to the specified project and directory.
+// UploadFile uploads a file to the specified project and directory. Files larger than
+// ClientArgs.ChunkedUpload.Threshold are uploaded in parts.
 // Parameters:
 // - projectID: ID of the project to which the file will be uploaded.
 // - directoryID: ID of the directory within the project where the file will be stored.
@@ -435,11 +448,12 @@ func (c *Client) UploadFileTo(projectID int, filePath string, projectPath string
 // - *mcmodel.File: Uploaded file metadata if the operation is successful.
 // - error: Describes the error encountered during file upload.
 func (c *Client) UploadFile(projectID, directoryID int, filePath string) (*mcmodel.File, error) {
+	op := c.startOperation("UploadFile", projectIDAttr(projectID))
 	var files [1]mcmodel.File
 
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil, err
+		return nil, op.end(err)
 	}
 
 	defer func(f *os.File) {
@@ -448,23 +462,38 @@ func (c *Client) UploadFile(projectID, directoryID int, filePath string) (*mcmod
 
 	fileName := filepath.Base(filePath)
 
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/files/%d/upload", projectID, directoryID)
-	resp, err := c.r().
-		SetFileReader("files[]", fileName, f).
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{&files}).
-		Post(url)
+	finfo, err := f.Stat()
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	if c.chunked.useChunks(finfo.Size()) {
+		file, err := op.uploadChunked(projectID, directoryID, f, fileName, finfo.Size())
+		return file, op.end(err)
+	}
 
-	if err := checkError(resp, err); err != nil {
-		return nil, err
+	err = op.send(apiRequest{
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/files/%d/upload", projectID, directoryID),
+		upload: true,
+		prepare: func(r *resty.Request) {
+			r.SetFileReader("files[]", fileName, f)
+		},
+	}, &DataWrapper{&files})
+	if err != nil {
+		return nil, op.end(err)
 	}
 
-	return &files[0], nil
+	op.addUploadedBytes(finfo.Size())
+	return &files[0], op.end(nil)
 }
 
 // DepositDataset deposits a dataset in a specified project given the project ID and request details.
 // It creates the dataset, uploads files into a unique directory, and sets file selection for the dataset.
 func (c *Client) DepositDataset(projectID int, req DepositDatasetRequest) (*mcmodel.Dataset, error) {
+	op := c.startOperation("DepositDataset", projectIDAttr(projectID))
+	oc := op.client()
+
 	// 1. Create the dataset.
 	createDatasetReq := CreateOrUpdateDatasetRequest{
 		Name:        req.Metadata.Name,
@@ -474,13 +503,16 @@ func (c *Client) DepositDataset(projectID int, req DepositDatasetRequest) (*mcmo
 		Funding:     req.Metadata.Funding,
 		//Experiments: nil,
 		//Communities: nil,
-		Tags:    req.Metadata.Tags,
-		Authors: req.Metadata.Authors,
+		Tags:       req.Metadata.Tags,
+		Authors:    req.Metadata.Authors,
+		Papers:     req.Metadata.Papers,
+		Attributes: req.Metadata.Attributes,
 	}
-	dataset, err := c.CreateDataset(projectID, createDatasetReq)
+	dataset, err := oc.CreateDataset(projectID, createDatasetReq)
 	if err != nil {
-		return nil, err
+		return nil, op.end(err)
 	}
+	op.span.SetAttributes(datasetIDAttr(dataset.ID))
 
 	// 2. Add the additional metadata to the dataset
 	// 3. Upload the files
@@ -491,9 +523,9 @@ func (c *Client) DepositDataset(projectID int, req DepositDatasetRequest) (*mcmo
 	// The directory will have the dataset UUID as its name. This is kind of a crappy
 	// solution, but for now let's start with that. We can revise this decision
 	// later on after discussing with Valentin.
-	dir, err := c.CreateDirectoryByPath(projectID, "/"+dataset.UUID)
+	dir, err := oc.CreateDirectoryByPath(projectID, "/"+dataset.UUID)
 	if err != nil {
-		return nil, err
+		return nil, op.end(err)
 	}
 
 	// Now for each of the files we are uploading, we need to append
@@ -505,10 +537,10 @@ func (c *Client) DepositDataset(projectID int, req DepositDatasetRequest) (*mcmo
 		} else {
 			fileDir = fileDir + "/"
 		}
-		_, err := c.UploadFileTo(projectID, file.File, dir.Path+file.Directory)
+		_, err := oc.UploadFileTo(projectID, file.File, dir.Path+file.Directory)
 		if err != nil {
 			// For now lets stop all uploads and return an error
-			return nil, err
+			return nil, op.end(err)
 		}
 	}
 
@@ -519,60 +551,33 @@ func (c *Client) DepositDataset(projectID int, req DepositDatasetRequest) (*mcmo
 		IncludeDirs:  []string{"/" + dataset.UUID},
 		ExcludeDirs:  nil,
 	}
-	dataset, err = c.UpdateDatasetFileSelection(projectID, dataset.ID, fileSelection)
+	dataset, err = oc.UpdateDatasetFileSelection(projectID, dataset.ID, fileSelection)
 	if err != nil {
-		return nil, err
+		return nil, op.end(err)
 	}
 
-	return dataset, nil
+	return dataset, op.end(nil)
 }
 
-// ListProjects lists all the projects a user is a member of
+// ListProjects lists all the projects a user is a member of. It retrieves every page of
+// projects; use IterateProjects or ListProjectsPage to page through them instead.
 func (c *Client) ListProjects() ([]mcmodel.Project, error) {
-	var projects []mcmodel.Project
-	url := c.BaseURL + "/projects"
-	resp, err := c.r().
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{&projects}).
-		Get(url)
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return projects, nil
+	return collect(c.IterateProjects(ListOptions{}))
 }
 
-// ListDatasets lists all the datasets in a project
+// ListDatasets lists all the datasets in a project. It retrieves every page of datasets;
+// use IterateDatasets or ListDatasetsPage to page through them instead.
 func (c *Client) ListDatasets(projectID int) ([]mcmodel.Dataset, error) {
-	var datasets []mcmodel.Dataset
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets", projectID)
-	resp, err := c.r().
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{&datasets}).
-		Get(url)
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return datasets, nil
+	return collect(c.IterateDatasets(projectID, ListOptions{}))
 }
 
 // MintDOIForDataset mints a new (findable) DOI for the dataset and assigns the DOI to it.
 func (c *Client) MintDOIForDataset(projectID, datasetID int, publishAsTestDataset bool) (*mcmodel.Dataset, error) {
-	var dataset mcmodel.Dataset
-
-	url := c.BaseURL + fmt.Sprintf("/projects/%d/datasets/%d/assign_doi%s", projectID, datasetID)
-
-	request := c.r().
-		SetError(&ErrorResponse{}).
-		SetResult(&DataWrapper{&dataset})
-
-	if publishAsTestDataset {
-		request = request.SetQueryParam("test", "true")
-	}
-
-	resp, err := request.Put(url)
-
-	if err := checkError(resp, err); err != nil {
-		return nil, err
-	}
-	return &dataset, nil
+	return do[mcmodel.Dataset](c, apiRequest{
+		op:     "MintDOIForDataset",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d/assign_doi", projectID, datasetID),
+		query:  testQuery(publishAsTestDataset),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
 }
@@ -0,0 +1,164 @@
+package mcapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Community is a group of published datasets on a common topic, curated by the community's
+// owner. A dataset's communities are set by ID in CreateOrUpdateDatasetRequest and
+// DatasetMetadata; use ListCommunities or FindCommunity to look up the IDs.
+type Community struct {
+	ID          int       `json:"id"`
+	UUID        string    `json:"uuid"`
+	Name        string    `json:"name"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	OwnerID     int       `json:"owner_id"`
+	Public      bool      `json:"public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CurationStatus is where a dataset submitted to a community is in the curation process.
+type CurationStatus string
+
+const (
+	// CurationPending is a submission the community's curators haven't reviewed yet.
+	CurationPending CurationStatus = "pending"
+
+	// CurationAccepted is a submission the curators accepted. The dataset is listed in
+	// the community.
+	CurationAccepted CurationStatus = "accepted"
+
+	// CurationRejected is a submission the curators turned down.
+	CurationRejected CurationStatus = "rejected"
+)
+
+// CommunitySubmission is a dataset submitted to a community.
+type CommunitySubmission struct {
+	CommunityID int            `json:"community_id"`
+	DatasetID   int            `json:"dataset_id"`
+	Status      CurationStatus `json:"status"`
+	Comment     string         `json:"comment"`
+	SubmittedAt time.Time      `json:"submitted_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// communitySubmissionRequest is the body for submitting a dataset to a community.
+type communitySubmissionRequest struct {
+	ProjectID   int `json:"project_id"`
+	CommunityID int `json:"community_id"`
+}
+
+// ListCommunitiesPage returns one page of the public communities.
+func (c *Client) ListCommunitiesPage(opts ListOptions) (*Page[Community], error) {
+	return listPage[Community](c, "ListCommunities", "/communities", opts)
+}
+
+// IterateCommunities returns an iterator over the public communities.
+func (c *Client) IterateCommunities(opts ListOptions) *Iterator[Community] {
+	return newIterator(opts, c.ListCommunitiesPage)
+}
+
+// ListCommunities lists all the public communities.
+func (c *Client) ListCommunities() ([]Community, error) {
+	return collect(c.IterateCommunities(ListOptions{}))
+}
+
+// GetCommunity retrieves a community by its ID.
+func (c *Client) GetCommunity(communityID int) (*Community, error) {
+	return do[Community](c, apiRequest{
+		op:     "GetCommunity",
+		method: http.MethodGet,
+		path:   apiPath("/communities/%d", communityID),
+		attrs:  []attribute.KeyValue{communityIDAttr(communityID)},
+	})
+}
+
+// FindCommunity returns the public community with the given name, ignoring case. If there is
+// no such community the returned error matches ErrNotFound.
+func (c *Client) FindCommunity(name string) (*Community, error) {
+	op := c.startOperation("FindCommunity")
+	it := op.client().IterateCommunities(ListOptions{})
+	for it.Next() {
+		if community := it.Value(); strings.EqualFold(community.Name, name) {
+			return &community, op.end(nil)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, op.end(err)
+	}
+
+	return nil, op.end(fmt.Errorf("%w: community %q", ErrNotFound, name))
+}
+
+// ListCommunityDatasetsPage returns one page of the datasets accepted into a community.
+func (c *Client) ListCommunityDatasetsPage(communityID int, opts ListOptions) (*Page[mcmodel.Dataset], error) {
+	path := apiPath("/communities/%d/datasets", communityID)
+	return listPage[mcmodel.Dataset](c, "ListCommunityDatasets", path, opts, communityIDAttr(communityID))
+}
+
+// IterateCommunityDatasets returns an iterator over the datasets accepted into a community.
+func (c *Client) IterateCommunityDatasets(communityID int, opts ListOptions) *Iterator[mcmodel.Dataset] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Dataset], error) {
+		return c.ListCommunityDatasetsPage(communityID, opts)
+	})
+}
+
+// ListCommunityDatasets lists all the datasets accepted into a community.
+func (c *Client) ListCommunityDatasets(communityID int) ([]mcmodel.Dataset, error) {
+	return collect(c.IterateCommunityDatasets(communityID, ListOptions{}))
+}
+
+// SubmitDatasetToCommunity submits a dataset for inclusion in a community. The dataset is
+// listed in the community once the community's curators accept it; check on the submission
+// with ListDatasetSubmissions.
+func (c *Client) SubmitDatasetToCommunity(projectID, datasetID, communityID int) (*CommunitySubmission, error) {
+	return do[CommunitySubmission](c, apiRequest{
+		op:     "SubmitDatasetToCommunity",
+		method: http.MethodPost,
+		path:   apiPath("/datasets/%d/communities", datasetID),
+		body:   communitySubmissionRequest{ProjectID: projectID, CommunityID: communityID},
+		attrs: []attribute.KeyValue{
+			projectIDAttr(projectID), datasetIDAttr(datasetID), communityIDAttr(communityID),
+		},
+	})
+}
+
+// WithdrawDatasetFromCommunity withdraws a dataset's submission to a community, or removes
+// the dataset from the community if it was already accepted.
+func (c *Client) WithdrawDatasetFromCommunity(projectID, datasetID, communityID int) error {
+	return c.send(apiRequest{
+		op:     "WithdrawDatasetFromCommunity",
+		method: http.MethodDelete,
+		path:   apiPath("/datasets/%d/communities/%d", datasetID, communityID),
+		query:  map[string]string{"project_id": strconv.Itoa(projectID)},
+		attrs: []attribute.KeyValue{
+			projectIDAttr(projectID), datasetIDAttr(datasetID), communityIDAttr(communityID),
+		},
+	}, nil)
+}
+
+// ListDatasetSubmissions lists a dataset's community submissions and where each one is in
+// the curation process.
+func (c *Client) ListDatasetSubmissions(projectID, datasetID int) ([]CommunitySubmission, error) {
+	submissions, err := do[[]CommunitySubmission](c, apiRequest{
+		op:     "ListDatasetSubmissions",
+		method: http.MethodGet,
+		path:   apiPath("/datasets/%d/communities", datasetID),
+		query:  map[string]string{"project_id": strconv.Itoa(projectID)},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *submissions, nil
+}
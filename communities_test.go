@@ -0,0 +1,76 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Communities(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handleFunc(http.MethodGet, "/communities", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, `{"data": [{"id": 3, "name": "Tomography"}], "meta": {"current_page": 2, "last_page": 2}}`)
+			return
+		}
+		writeJSON(w, `{"data": [{"id": 2, "name": "Alloys"}], "meta": {"current_page": 1, "last_page": 2}}`)
+	})
+	srv.handle(http.MethodGet, "/communities/3", `{"data": {"id": 3, "name": "Tomography", "public": true}}`)
+	srv.handle(http.MethodGet, "/communities/3/datasets", `{"data": [{"id": 5, "name": "scan"}]}`)
+	c := srv.client()
+
+	communities, err := c.ListCommunities()
+	if err != nil || len(communities) != 2 {
+		t.Fatalf("ListCommunities returned %+v, %v", communities, err)
+	}
+
+	community, err := c.FindCommunity("tomography")
+	if err != nil || community.ID != 3 {
+		t.Errorf("FindCommunity returned %+v, %v", community, err)
+	}
+
+	if _, err := c.FindCommunity("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if community, err := c.GetCommunity(3); err != nil || !community.Public {
+		t.Errorf("GetCommunity returned %+v, %v", community, err)
+	}
+
+	if datasets, err := c.ListCommunityDatasets(3); err != nil || len(datasets) != 1 || datasets[0].ID != 5 {
+		t.Errorf("ListCommunityDatasets returned %+v, %v", datasets, err)
+	}
+}
+
+func TestClient_CommunitySubmissions(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/datasets/5/communities", `{"data": {"community_id": 3, "dataset_id": 5, "status": "pending"}}`)
+	srv.handle(http.MethodGet, "/datasets/5/communities", `{"data": [{"community_id": 3, "dataset_id": 5, "status": "accepted"}]}`)
+	srv.handle(http.MethodDelete, "/datasets/5/communities/3", `{}`)
+	c := srv.client()
+
+	submission, err := c.SubmitDatasetToCommunity(1, 5, 3)
+	if err != nil || submission.Status != CurationPending {
+		t.Fatalf("SubmitDatasetToCommunity returned %+v, %v", submission, err)
+	}
+
+	var body communitySubmissionRequest
+	if err := json.Unmarshal(srv.received()[0].Body, &body); err != nil || body.ProjectID != 1 || body.CommunityID != 3 {
+		t.Errorf("unexpected submission request %+v, %v", body, err)
+	}
+
+	submissions, err := c.ListDatasetSubmissions(1, 5)
+	if err != nil || len(submissions) != 1 || submissions[0].Status != CurationAccepted {
+		t.Errorf("ListDatasetSubmissions returned %+v, %v", submissions, err)
+	}
+
+	if err := c.WithdrawDatasetFromCommunity(1, 5, 3); err != nil {
+		t.Error(err)
+	}
+
+	received := srv.received()
+	if last := received[len(received)-1]; last.RawQuery != "project_id=1" {
+		t.Errorf("unexpected withdraw request %+v", last)
+	}
+}
@@ -3,10 +3,10 @@ package mcapi
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/user"
 	"path/filepath"
+	"sync"
 
 	"github.com/materials-commons/config"
 	"github.com/materials-commons/config/cfg"
@@ -14,25 +14,70 @@ import (
 	"github.com/materials-commons/config/loader"
 )
 
-func init() {
-	h := setupConfigHandler()
-	config.Init(h)
+// ConfigOptions controls how LoadConfig builds the configuration. The zero value
+// loads $HOME/.materialscommons/config.json if it exists.
+type ConfigOptions struct {
+	// ConfigPath is an alternate config file to load instead of
+	// $HOME/.materialscommons/config.json. Unlike the default file, it is an
+	// error if ConfigPath is set and the file doesn't exist.
+	ConfigPath string
+
+	// SkipUserConfig skips the config file entirely so that only the environment
+	// and defaults are used. ConfigPath is ignored when this is set.
+	SkipUserConfig bool
+}
+
+var (
+	configMu     sync.Mutex
+	configLoaded bool
+)
+
+// LoadConfig sets up the package configuration using opts. It can be called more than
+// once; each call replaces the previously loaded configuration. Nothing is loaded when
+// the package is imported, so callers that need control over where configuration comes
+// from should call LoadConfig before creating a Client.
+func LoadConfig(opts ConfigOptions) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return loadConfig(opts)
+}
+
+// ensureConfig loads the default configuration the first time it is called, unless
+// LoadConfig has already been called.
+func ensureConfig() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if configLoaded {
+		return nil
+	}
+	return loadConfig(ConfigOptions{})
+}
+
+// loadConfig does the work for LoadConfig and ensureConfig. configMu must be held.
+func loadConfig(opts ConfigOptions) error {
+	h, err := setupConfigHandler(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Init(h); err != nil {
+		return fmt.Errorf("unable to initialize config: %w", err)
+	}
+
+	configLoaded = true
+	return nil
 }
 
 // setupConfigHandler creates the handler for the mc package. It sets up a
 // multi handler. If the user has setup a config.json in their .materialscommons
-// directory then it will add that to the handler list. Handlers are searched in
-// the following order: env - (optional) config file - defaults.
+// directory (or passed an alternate path in opts) then it will add that to the
+// handler list. Handlers are searched in the following order: env - (optional)
+// config file - defaults.
 //
 // This means that configuration set in the environment will override all other
 // settings, then it will check the config file (if one is setup), and finally
 // it will use the defaults.
-func setupConfigHandler() cfg.Handler {
-	u, err := user.Current()
-	if err != nil {
-		panic(fmt.Sprintf("Couldn't determine current user: %s", err))
-	}
-
+func setupConfigHandler(opts ConfigOptions) (cfg.Handler, error) {
 	// Set up the handlers. The order matters as it will search for
 	// configuration entries first to last, stopping when it finds
 	// one. This means that each entry overrides settings below it.
@@ -40,33 +85,74 @@ func setupConfigHandler() cfg.Handler {
 		handler.Env(),
 	}
 
-	configFile := filepath.Join(u.HomeDir, ".materialscommons/config.json")
-	if l := getUserConfigLoader(configFile); l != nil {
-		handlers = append(handlers, handler.Loader(l))
+	if !opts.SkipUserConfig {
+		l, err := getConfigLoader(opts.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if l != nil {
+			handlers = append(handlers, handler.Loader(l))
+		}
 	}
 
 	defaultHandler := handler.Map()
 	loadDefaults(defaultHandler)
 	handlers = append(handlers, defaultHandler)
-	return handler.Sync(handler.Multi(handlers...))
+	return handler.Sync(handler.Multi(handlers...)), nil
 }
 
-// getUserConfigLoader returns a json loader if the $HOME/.materialscommons/config.json
-// file exists. It will panic if the file exists but cannot be read.
-func getUserConfigLoader(configFile string) cfg.Loader {
-	if _, err := os.Stat(configFile); !os.IsNotExist(err) {
-		contents, err := ioutil.ReadFile(configFile)
-		if err != nil {
-			panic(fmt.Sprintf("%s exists but can't be read: %s", configFile, err))
-		}
-		return loader.JSON(bytes.NewReader(contents))
+// getConfigLoader returns a json loader for the config file. When configPath is blank it
+// looks for $HOME/.materialscommons/config.json and returns a nil loader if that file
+// doesn't exist. An explicitly given configPath must exist.
+func getConfigLoader(configPath string) (cfg.Loader, error) {
+	if configPath != "" {
+		return getUserConfigLoader(configPath, true)
 	}
-	return nil
+
+	homeDir, err := userHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return getUserConfigLoader(filepath.Join(homeDir, ".materialscommons/config.json"), false)
+}
+
+// userHomeDir determines the home directory of the current user. It falls back to $HOME
+// when the user can't be looked up, which happens for processes running as a uid that
+// has no passwd entry (common in containers).
+func userHomeDir() (string, error) {
+	if u, err := user.Current(); err == nil && u.HomeDir != "" {
+		return u.HomeDir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("couldn't determine home directory for current user: %w", err)
+	}
+
+	return homeDir, nil
+}
+
+// getUserConfigLoader returns a json loader for configFile. If the file doesn't exist
+// then it returns a nil loader, or an error when mustExist is true. It returns an
+// error if the file exists but cannot be read.
+func getUserConfigLoader(configFile string, mustExist bool) (cfg.Loader, error) {
+	contents, err := os.ReadFile(configFile)
+	switch {
+	case os.IsNotExist(err) && !mustExist:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("%s can't be read: %w", configFile, err)
+	}
+
+	return loader.JSON(bytes.NewReader(contents)), nil
 }
 
 // loadDefaults sets up the default values for the following configuration keys:
-//     mcurl: https://materialscommons.org/api
-//     mclogging: info
+//
+//	mcurl: https://materialscommons.org/api
+//	mclogging: info
 func loadDefaults(h cfg.Handler) {
 	h.Set("mcurl", "https://materialscommons.org/api")
 	h.Set("mclogging", "info")
@@ -0,0 +1,52 @@
+package mcapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/materials-commons/config"
+)
+
+func TestLoadConfig_SkipUserConfig(t *testing.T) {
+	if err := LoadConfig(ConfigOptions{SkipUserConfig: true, ConfigPath: "/does/not/exist"}); err != nil {
+		t.Fatalf("expected no error when skipping user config, got %s", err)
+	}
+
+	if url := config.GetString("mcurl"); url != "https://materialscommons.org/api" {
+		t.Errorf("expected default mcurl, got %q", url)
+	}
+}
+
+func TestLoadConfig_ConfigPath(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"mclogging": "debug"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadConfig(ConfigOptions{ConfigPath: configPath}); err != nil {
+		t.Fatalf("unable to load config from %s: %s", configPath, err)
+	}
+
+	if level := config.GetString("mclogging"); level != "debug" {
+		t.Errorf("expected mclogging to be debug, got %q", level)
+	}
+
+	if url := config.GetString("mcurl"); url != "https://materialscommons.org/api" {
+		t.Errorf("expected default mcurl, got %q", url)
+	}
+}
+
+func TestLoadConfig_MissingConfigPath(t *testing.T) {
+	err := LoadConfig(ConfigOptions{ConfigPath: filepath.Join(t.TempDir(), "missing.json")})
+	if err == nil {
+		t.Fatal("expected error for missing config path")
+	}
+}
+
+func TestLoadConfig_UnreadableConfigPath(t *testing.T) {
+	// A directory exists but can't be read as a file.
+	if err := LoadConfig(ConfigOptions{ConfigPath: t.TempDir()}); err == nil {
+		t.Fatal("expected error for unreadable config path")
+	}
+}
@@ -0,0 +1,393 @@
+package mcapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// datasetWithSelection is a dataset as returned by the API along with its file selection.
+// mcmodel.Dataset can't be used on its own because its FileSelection field has no json tag,
+// so it never picks up the file_selection key the server sends.
+type datasetWithSelection struct {
+	mcmodel.Dataset
+	FileSelection json.RawMessage `json:"file_selection"`
+}
+
+// getDatasetSelection retrieves a dataset along with its file selection. The server sends
+// the selection either as an object or as a string of json. A dataset whose selection is
+// null or empty has nothing selected, but a response without a file_selection key is an
+// error, so that callers that replace the selection never mistake a missing selection for
+// an empty one.
+func (c *Client) getDatasetSelection(projectID, datasetID int) (*mcmodel.Dataset, DatasetFileSelection, error) {
+	ds, err := do[datasetWithSelection](c, apiRequest{
+		op:     "GetDataset",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/datasets/%d", projectID, datasetID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+	if err != nil {
+		return nil, DatasetFileSelection{}, err
+	}
+
+	if len(ds.FileSelection) == 0 {
+		return nil, DatasetFileSelection{}, fmt.Errorf("dataset %d was returned without its file_selection", datasetID)
+	}
+
+	selection, err := decodeFileSelection(ds.FileSelection)
+	if err != nil {
+		return nil, DatasetFileSelection{}, fmt.Errorf("unable to read the file selection of dataset %d: %w", datasetID, err)
+	}
+
+	return &ds.Dataset, selection, nil
+}
+
+// decodeFileSelection decodes a file selection sent as an object, a string of json or null.
+func decodeFileSelection(data json.RawMessage) (DatasetFileSelection, error) {
+	var selection DatasetFileSelection
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return selection, err
+		}
+		data = []byte(strings.TrimSpace(encoded))
+	}
+
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return selection, nil
+	}
+
+	err := json.Unmarshal(data, &selection)
+	return selection, err
+}
+
+// Includes returns true if the file at filePath is part of the selection. A file listed in
+// ExcludeFiles or IncludeFiles is excluded or included. Otherwise the closest of its parent
+// directories listed in ExcludeDirs or IncludeDirs decides. Files that aren't covered by
+// any entry are excluded.
+func (s DatasetFileSelection) Includes(filePath string) bool {
+	filePath = path.Clean("/" + filePath)
+	if containsPath(s.ExcludeFiles, filePath) {
+		return false
+	}
+
+	if containsPath(s.IncludeFiles, filePath) {
+		return true
+	}
+
+	return s.includesDir(path.Dir(filePath))
+}
+
+// containsPath returns true if paths contains p, ignoring differences such as trailing slashes.
+func containsPath(paths []string, p string) bool {
+	for _, candidate := range paths {
+		if path.Clean("/"+candidate) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDatasetFiles resolves a dataset's file selection into the files in the dataset. The
+// Path of each returned file is set to its path in the project, and the files are sorted by
+// path. It returns an error if the dataset's selection can't be read.
+func (c *Client) ListDatasetFiles(projectID, datasetID int) ([]mcmodel.File, error) {
+	op := c.startOperation("ListDatasetFiles", projectIDAttr(projectID), datasetIDAttr(datasetID))
+	files, err := op.client().listDatasetFiles(projectID, datasetID)
+	return files, op.end(err)
+}
+
+// listDatasetFiles does the work for ListDatasetFiles.
+func (c *Client) listDatasetFiles(projectID, datasetID int) ([]mcmodel.File, error) {
+	_, selection, err := c.getDatasetSelection(projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := c.GetProjectTree(projectID, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []mcmodel.File
+	var visit func(t *FileTree)
+	visit = func(t *FileTree) {
+		if !t.File.IsDir() {
+			if selection.Includes(t.Path) {
+				f := t.File
+				f.Path = t.Path
+				files = append(files, f)
+			}
+			return
+		}
+
+		for _, child := range t.Children {
+			visit(child)
+		}
+	}
+	visit(tree)
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// DatasetDownloadOptions control DownloadDataset.
+type DatasetDownloadOptions struct {
+	// Tree downloads the selected files into a directory tree even when the dataset is
+	// published and has a zip file.
+	Tree bool
+
+	// Concurrency is the number of files downloaded at once when downloading a tree. It
+	// defaults to 4.
+	Concurrency int
+}
+
+// DatasetDownload reports what DownloadDataset did. Only one of its fields is set.
+type DatasetDownload struct {
+	// ZipPath is the local path of the dataset's zip file, when that was downloaded.
+	ZipPath string
+
+	// Files reports the files downloaded into a tree.
+	Files *MirrorResult
+}
+
+// DownloadDataset downloads a dataset into localDir. For a published dataset it downloads
+// the zip file the server built when the dataset was published. Otherwise, or when opts.Tree
+// is set, it downloads the dataset's files into localDir at their project paths. As with
+// MirrorProject, a tree download can be run again to finish an interrupted download.
+func (c *Client) DownloadDataset(projectID, datasetID int, localDir string, opts DatasetDownloadOptions) (*DatasetDownload, error) {
+	op := c.startOperation("DownloadDataset", projectIDAttr(projectID), datasetIDAttr(datasetID))
+	download, err := op.client().downloadDataset(projectID, datasetID, localDir, opts)
+	return download, op.end(err)
+}
+
+// downloadDataset does the work for DownloadDataset.
+func (c *Client) downloadDataset(projectID, datasetID int, localDir string, opts DatasetDownloadOptions) (*DatasetDownload, error) {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return nil, err
+	}
+
+	ds, err := c.GetDataset(projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ds.PublishedAt.IsZero() && !opts.Tree {
+		zipPath := filepath.Join(localDir, filepath.Base(ds.ZipfilePath("")))
+		req := apiRequest{
+			op:     "DownloadDatasetZip",
+			method: http.MethodGet,
+			path:   apiPath("/projects/%d/datasets/%d/download_zipfile", projectID, datasetID),
+			attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+		}
+		if err := c.downloadDatasetZip(req, zipPath); err != nil {
+			return nil, err
+		}
+		return &DatasetDownload{ZipPath: zipPath}, nil
+	}
+
+	files, err := c.listDatasetFiles(projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make([]*FileTree, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(mirrorLocalPath(localDir, f.Path)), 0755); err != nil {
+			return nil, err
+		}
+		trees = append(trees, &FileTree{File: f, Path: f.Path})
+	}
+
+	result := c.mirrorFiles(projectFiles(projectID), localDir, trees, MirrorOptions{Concurrency: opts.Concurrency})
+	return &DatasetDownload{Files: result}, result.err()
+}
+
+// downloadDatasetZip downloads a published dataset's zip file, using req, to zipPath. The zip
+// is written to a partial file first so that an interrupted download doesn't leave a
+// truncated zip.
+func (c *Client) downloadDatasetZip(req apiRequest, zipPath string) error {
+	partial := zipPath + partialSuffix
+	f, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.download(req, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(partial)
+		return err
+	}
+
+	return os.Rename(partial, zipPath)
+}
+
+// AddFilesToDataset adds files to a dataset's current file selection, returning the updated
+// dataset. Each path must be a file in the project. Paths already in the dataset are ignored.
+func (c *Client) AddFilesToDataset(projectID, datasetID int, filePaths ...string) (*mcmodel.Dataset, error) {
+	return c.editDatasetSelection("AddFilesToDataset", projectID, datasetID, filePaths, false, true,
+		func(s *DatasetFileSelection, p string) {
+			s.ExcludeFiles = removePath(s.ExcludeFiles, p)
+			if !s.Includes(p) {
+				s.IncludeFiles = addPath(s.IncludeFiles, p)
+			}
+		})
+}
+
+// RemoveFilesFromDataset removes files from a dataset's current file selection, returning
+// the updated dataset. Files that are in the dataset because their directory is selected are
+// explicitly excluded. Each path must be a file in the project, unless it's listed in the
+// selection, so that files deleted from the project can still be removed.
+func (c *Client) RemoveFilesFromDataset(projectID, datasetID int, filePaths ...string) (*mcmodel.Dataset, error) {
+	return c.editDatasetSelection("RemoveFilesFromDataset", projectID, datasetID, filePaths, false, false,
+		func(s *DatasetFileSelection, p string) {
+			s.IncludeFiles = removePath(s.IncludeFiles, p)
+			if s.Includes(p) {
+				s.ExcludeFiles = addPath(s.ExcludeFiles, p)
+			}
+		})
+}
+
+// AddDirsToDataset adds directories, and everything under them, to a dataset's current file
+// selection, returning the updated dataset. Files and directories under them that were
+// excluded are included again. Each path must be a directory in the project.
+func (c *Client) AddDirsToDataset(projectID, datasetID int, dirPaths ...string) (*mcmodel.Dataset, error) {
+	return c.editDatasetSelection("AddDirsToDataset", projectID, datasetID, dirPaths, true, true,
+		func(s *DatasetFileSelection, p string) {
+			s.ExcludeDirs = removePathsUnder(s.ExcludeDirs, p)
+			s.ExcludeFiles = removePathsUnder(s.ExcludeFiles, p)
+			if !s.includesDir(p) {
+				s.IncludeDirs = addPath(s.IncludeDirs, p)
+			}
+		})
+}
+
+// RemoveDirsFromDataset removes directories, and everything under them, from a dataset's
+// current file selection, returning the updated dataset. Files and directories under them
+// that were included are dropped from the selection, and a directory that is in the dataset
+// because a parent directory is selected is explicitly excluded. Each path must be a
+// directory in the project, unless it's listed in the selection.
+func (c *Client) RemoveDirsFromDataset(projectID, datasetID int, dirPaths ...string) (*mcmodel.Dataset, error) {
+	return c.editDatasetSelection("RemoveDirsFromDataset", projectID, datasetID, dirPaths, true, false,
+		func(s *DatasetFileSelection, p string) {
+			s.IncludeDirs = removePathsUnder(s.IncludeDirs, p)
+			s.IncludeFiles = removePathsUnder(s.IncludeFiles, p)
+			s.ExcludeDirs = removePathsUnder(s.ExcludeDirs, p)
+			s.ExcludeFiles = removePathsUnder(s.ExcludeFiles, p)
+			if s.includesDir(p) {
+				s.ExcludeDirs = addPath(s.ExcludeDirs, p)
+			}
+		})
+}
+
+// editDatasetSelection fetches a dataset's selection, checks that each of paths exists in
+// the project, applies edit to the selection for each path, and saves the result. When
+// adding is false, paths already listed in the selection aren't checked.
+func (c *Client) editDatasetSelection(opName string, projectID, datasetID int, paths []string, isDir, adding bool, edit func(s *DatasetFileSelection, p string)) (*mcmodel.Dataset, error) {
+	op := c.startOperation(opName, projectIDAttr(projectID), datasetIDAttr(datasetID))
+	oc := op.client()
+
+	_, selection, err := oc.getDatasetSelection(projectID, datasetID)
+	if err != nil {
+		return nil, op.end(err)
+	}
+	selection = selection.cleaned()
+
+	for _, p := range paths {
+		p = path.Clean("/" + p)
+		if adding || !selection.lists(p) {
+			if _, err := oc.resolve(projectID, PathRef(p), isDir); err != nil {
+				return nil, op.end(fmt.Errorf("unable to find %s in project: %w", p, err))
+			}
+		}
+		edit(&selection, p)
+	}
+
+	ds, err := oc.UpdateDatasetFileSelection(projectID, datasetID, selection)
+	return ds, op.end(err)
+}
+
+// includesDir returns true if the closest entry in IncludeDirs or ExcludeDirs for the
+// directory at dirPath, or one of its parents, is an include.
+func (s DatasetFileSelection) includesDir(dirPath string) bool {
+	for dir := dirPath; ; dir = path.Dir(dir) {
+		switch {
+		case containsPath(s.ExcludeDirs, dir):
+			return false
+		case containsPath(s.IncludeDirs, dir):
+			return true
+		case dir == "/":
+			return false
+		}
+	}
+}
+
+// lists returns true if p appears in any of the selection's lists.
+func (s DatasetFileSelection) lists(p string) bool {
+	return containsPath(s.IncludeFiles, p) || containsPath(s.ExcludeFiles, p) ||
+		containsPath(s.IncludeDirs, p) || containsPath(s.ExcludeDirs, p)
+}
+
+// cleaned returns a copy of s with every path cleaned and duplicates removed.
+func (s DatasetFileSelection) cleaned() DatasetFileSelection {
+	return DatasetFileSelection{
+		IncludeFiles: dedupePaths(s.IncludeFiles),
+		ExcludeFiles: dedupePaths(s.ExcludeFiles),
+		IncludeDirs:  dedupePaths(s.IncludeDirs),
+		ExcludeDirs:  dedupePaths(s.ExcludeDirs),
+	}
+}
+
+// dedupePaths returns the cleaned paths in paths, without duplicates, in their original order.
+func dedupePaths(paths []string) []string {
+	deduped := make([]string, 0, len(paths))
+	for _, p := range paths {
+		deduped = addPath(deduped, path.Clean("/"+p))
+	}
+	return deduped
+}
+
+// addPath returns paths with p appended, unless it's already there.
+func addPath(paths []string, p string) []string {
+	if containsPath(paths, p) {
+		return paths
+	}
+	return append(paths, p)
+}
+
+// removePath returns paths without p.
+func removePath(paths []string, p string) []string {
+	kept := paths[:0]
+	for _, candidate := range paths {
+		if path.Clean("/"+candidate) != p {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
+
+// removePathsUnder returns paths without dir and anything under it.
+func removePathsUnder(paths []string, dir string) []string {
+	kept := paths[:0]
+	for _, candidate := range paths {
+		candidate = path.Clean("/" + candidate)
+		if candidate != dir && !strings.HasPrefix(candidate, strings.TrimSuffix(dir, "/")+"/") {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,273 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatasetFileSelection_Includes(t *testing.T) {
+	s := DatasetFileSelection{
+		IncludeFiles: []string{"/raw/skip/keep.txt"},
+		ExcludeFiles: []string{"/raw/bad.txt"},
+		IncludeDirs:  []string{"/raw/"},
+		ExcludeDirs:  []string{"/raw/skip"},
+	}
+
+	tests := map[string]bool{
+		"/raw/a.txt":         true,
+		"/raw/deep/b.txt":    true,
+		"/raw/bad.txt":       false,
+		"/raw/skip/c.txt":    false,
+		"/raw/skip/keep.txt": true,
+		"/other/d.txt":       false,
+		"raw/e.txt":          true,
+	}
+
+	for p, expected := range tests {
+		if got := s.Includes(p); got != expected {
+			t.Errorf("Includes(%q) = %t, expected %t", p, got, expected)
+		}
+	}
+}
+
+// datasetFilesServer serves a project with three files, and a dataset selecting two of them.
+func datasetFilesServer(t *testing.T, publishedAt string) *fakeServer {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/files", `{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory", "directory_id": 1},
+		{"id": 2, "name": "raw", "path": "/raw", "mime_type": "directory", "directory_id": 1},
+		{"id": 10, "name": "a.txt", "directory_id": 1, "size": 1},
+		{"id": 11, "name": "b.txt", "directory_id": 2, "size": 1},
+		{"id": 12, "name": "c.txt", "directory_id": 2, "size": 1}]}`)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "My Data", "uuid": "abc",
+		"published_at": "`+publishedAt+`",
+		"file_selection": {"include_files": ["/a.txt"], "include_dirs": ["/raw"], "exclude_files": ["/raw/c.txt"]}}}`)
+	for id, data := range map[string]string{"10": "a", "11": "b"} {
+		data := data
+		srv.handleFunc(http.MethodGet, "/projects/1/files/"+id+"/download", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(data))
+		})
+	}
+	return srv
+}
+
+func TestClient_ListDatasetFiles(t *testing.T) {
+	srv := datasetFilesServer(t, "0001-01-01T00:00:00Z")
+
+	files, err := srv.client().ListDatasetFiles(1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 || files[0].Path != "/a.txt" || files[1].Path != "/raw/b.txt" {
+		t.Errorf("unexpected files %+v", files)
+	}
+}
+
+func TestClient_ListDatasetFilesNeedsSelection(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "My Data"}}`)
+
+	if _, err := srv.client().ListDatasetFiles(1, 5); err == nil {
+		t.Error("expected an error when the dataset has no file_selection")
+	}
+
+	if _, err := srv.client().DownloadDataset(1, 5, t.TempDir(), DatasetDownloadOptions{}); err == nil {
+		t.Error("expected DownloadDataset to fail when the dataset has no file_selection")
+	}
+}
+
+func TestClient_DownloadDatasetTree(t *testing.T) {
+	srv := datasetFilesServer(t, "0001-01-01T00:00:00Z")
+	localDir := t.TempDir()
+
+	download, err := srv.client().DownloadDataset(1, 5, localDir, DatasetDownloadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if download.ZipPath != "" || len(download.Files.Downloaded) != 2 {
+		t.Errorf("unexpected download %+v", download)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(localDir, "raw", "b.txt")); err != nil || string(b) != "b" {
+		t.Errorf("raw/b.txt: got %q, %v", b, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(localDir, "raw", "c.txt")); err == nil {
+		t.Error("excluded file should not be downloaded")
+	}
+}
+
+func TestClient_DownloadPublishedDatasetZip(t *testing.T) {
+	srv := datasetFilesServer(t, "2024-05-01T00:00:00Z")
+	srv.handleFunc(http.MethodGet, "/projects/1/datasets/5/download_zipfile", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("PK zip"))
+	})
+	localDir := t.TempDir()
+
+	download, err := srv.client().DownloadDataset(1, 5, localDir, DatasetDownloadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if download.ZipPath != filepath.Join(localDir, "my-data.zip") || download.Files != nil {
+		t.Errorf("unexpected download %+v", download)
+	}
+
+	if b, err := os.ReadFile(download.ZipPath); err != nil || string(b) != "PK zip" {
+		t.Errorf("zip: got %q, %v", b, err)
+	}
+}
+
+// selectionServer serves a dataset with the given selection json, recording the selection
+// sent to change_file_selection.
+func selectionServer(t *testing.T, selection string, files map[string]string) (*fakeServer, *DatasetFileSelection) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "file_selection": `+selection+`}}`)
+	handleByPath(srv, files)
+
+	var sent DatasetFileSelection
+	srv.handleFunc(http.MethodPut, "/projects/1/datasets/5/change_file_selection", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		writeJSON(w, `{"data": {"id": 5}}`)
+	})
+	return srv, &sent
+}
+
+func TestClient_AddAndRemoveDatasetFiles(t *testing.T) {
+	srv, sent := selectionServer(t,
+		`{"include_files": ["/a.txt", "/a.txt/"], "include_dirs": ["/raw"], "exclude_files": ["/raw/b.txt"]}`,
+		map[string]string{
+			"/raw/b.txt": `{"id": 11, "name": "b.txt"}`,
+			"/new.txt":   `{"id": 12, "name": "new.txt"}`,
+			"/raw/c.txt": `{"id": 13, "name": "c.txt"}`,
+			"/raw":       `{"id": 2, "name": "raw", "mime_type": "directory"}`,
+		})
+	c := srv.client()
+
+	if _, err := c.AddFilesToDataset(1, 5, "/raw/b.txt", "new.txt", "/new.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(sent.IncludeFiles) != "[/a.txt /new.txt]" || len(sent.ExcludeFiles) != 0 {
+		t.Errorf("unexpected selection after add %+v", *sent)
+	}
+
+	// a.txt no longer exists in the project, but it's in the selection so it can be removed.
+	if _, err := c.RemoveFilesFromDataset(1, 5, "/a.txt", "/raw/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent.IncludeFiles) != 0 || fmt.Sprint(sent.ExcludeFiles) != "[/raw/b.txt /raw/c.txt]" {
+		t.Errorf("unexpected selection after remove %+v", *sent)
+	}
+
+	if _, err := c.AddFilesToDataset(1, 5, "/missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound adding a missing file, got %v", err)
+	}
+
+	if _, err := c.AddFilesToDataset(1, 5, "/raw"); err == nil {
+		t.Error("expected error adding a directory as a file")
+	}
+}
+
+func TestClient_AddAndRemoveDatasetDirs(t *testing.T) {
+	srv, sent := selectionServer(t, `{"include_dirs": ["/raw"], "exclude_dirs": ["/old"]}`,
+		map[string]string{
+			"/raw":         `{"id": 2, "name": "raw", "mime_type": "directory"}`,
+			"/raw/scratch": `{"id": 3, "name": "scratch", "mime_type": "directory"}`,
+			"/old":         `{"id": 4, "name": "old", "mime_type": "directory"}`,
+		})
+	c := srv.client()
+
+	if _, err := c.AddDirsToDataset(1, 5, "/old", "/raw/scratch"); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(sent.IncludeDirs) != "[/raw /old]" || len(sent.ExcludeDirs) != 0 {
+		t.Errorf("unexpected selection after add %+v", *sent)
+	}
+
+	if _, err := c.RemoveDirsFromDataset(1, 5, "/raw/scratch"); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(sent.IncludeDirs) != "[/raw]" || fmt.Sprint(sent.ExcludeDirs) != "[/old /raw/scratch]" {
+		t.Errorf("unexpected selection after remove %+v", *sent)
+	}
+}
+
+func TestDecodeFileSelection(t *testing.T) {
+	tests := map[string]string{
+		`{"include_dirs": ["/raw"]}`:       "[/raw]",
+		`"{\"include_dirs\": [\"/raw\"]}"`: "[/raw]",
+		`null`:                             "[]",
+		`""`:                               "[]",
+	}
+
+	for data, expected := range tests {
+		selection, err := decodeFileSelection(json.RawMessage(data))
+		if err != nil || fmt.Sprint(selection.IncludeDirs) != expected {
+			t.Errorf("decodeFileSelection(%s) = %+v, %v", data, selection, err)
+		}
+	}
+
+	if _, err := decodeFileSelection(json.RawMessage(`"not json"`)); err == nil {
+		t.Error("expected an error decoding an invalid selection")
+	}
+}
+
+func TestClient_EditDatasetSelectionNeedsSelection(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "FileSelection": "{}"}}`)
+	handleByPath(srv, map[string]string{"/a.txt": `{"id": 10, "name": "a.txt"}`})
+
+	if _, err := srv.client().AddFilesToDataset(1, 5, "/a.txt"); err == nil {
+		t.Fatal("expected an error when the dataset has no file_selection")
+	}
+
+	for _, r := range srv.received() {
+		if r.Method == http.MethodPut {
+			t.Errorf("the selection should not be replaced, got %s %s", r.Method, r.Path)
+		}
+	}
+}
+
+func TestClient_RemoveDatasetDirWithNestedIncludes(t *testing.T) {
+	srv, sent := selectionServer(t,
+		`{"include_dirs": ["/a", "/a/b", "/ab"], "include_files": ["/a/x.txt", "/y.txt"], "exclude_files": ["/a/b/z.txt"]}`,
+		map[string]string{"/a": `{"id": 2, "name": "a", "mime_type": "directory"}`})
+
+	if _, err := srv.client().RemoveDirsFromDataset(1, 5, "/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"/a/b/c.txt", "/a/x.txt", "/a/b/z.txt"} {
+		if sent.Includes(p) {
+			t.Errorf("%s should no longer be selected, selection is %+v", p, *sent)
+		}
+	}
+
+	if !sent.Includes("/ab/c.txt") || !sent.Includes("/y.txt") {
+		t.Errorf("paths outside /a should still be selected, selection is %+v", *sent)
+	}
+}
+
+func TestClient_AddDatasetDirIncludesExcludedChildren(t *testing.T) {
+	srv, sent := selectionServer(t, `{"exclude_dirs": ["/a/b"], "exclude_files": ["/a/x.txt"]}`,
+		map[string]string{"/a": `{"id": 2, "name": "a", "mime_type": "directory"}`})
+
+	if _, err := srv.client().AddDirsToDataset(1, 5, "/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sent.Includes("/a/b/c.txt") || !sent.Includes("/a/x.txt") {
+		t.Errorf("everything under /a should be selected, selection is %+v", *sent)
+	}
+}
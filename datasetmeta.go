@@ -0,0 +1,207 @@
+package mcapi
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DatasetPaper is a paper attached to a dataset.
+type DatasetPaper struct {
+	ID int `json:"id"`
+	Paper
+}
+
+// DatasetAttribute is an attribute attached to a dataset, such as the instrument used to
+// collect its data.
+type DatasetAttribute struct {
+	ID int `json:"id"`
+	Attribute
+}
+
+// tagsRequest is the body for the call that sets a dataset's tags.
+type tagsRequest struct {
+	Tags []Tag `json:"tags"`
+}
+
+// ListDatasetPapers lists the papers attached to a dataset.
+func (c *Client) ListDatasetPapers(projectID, datasetID int) ([]DatasetPaper, error) {
+	papers, err := do[[]DatasetPaper](c, apiRequest{
+		op:     "ListDatasetPapers",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/datasets/%d/papers", projectID, datasetID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *papers, nil
+}
+
+// AddDatasetPaper attaches a paper to a dataset. This can be done after the dataset is
+// published.
+func (c *Client) AddDatasetPaper(projectID, datasetID int, paper Paper) (*DatasetPaper, error) {
+	return do[DatasetPaper](c, apiRequest{
+		op:     "AddDatasetPaper",
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/datasets/%d/papers", projectID, datasetID),
+		body:   paper,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// UpdateDatasetPaper replaces the details of a paper attached to a dataset.
+func (c *Client) UpdateDatasetPaper(projectID, datasetID, paperID int, paper Paper) (*DatasetPaper, error) {
+	return do[DatasetPaper](c, apiRequest{
+		op:     "UpdateDatasetPaper",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d/papers/%d", projectID, datasetID, paperID),
+		body:   paper,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// RemoveDatasetPaper removes a paper from a dataset.
+func (c *Client) RemoveDatasetPaper(projectID, datasetID, paperID int) error {
+	return c.send(apiRequest{
+		op:     "RemoveDatasetPaper",
+		method: http.MethodDelete,
+		path:   apiPath("/projects/%d/datasets/%d/papers/%d", projectID, datasetID, paperID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	}, nil)
+}
+
+// ListDatasetAttributes lists the dataset-level attributes of a dataset.
+func (c *Client) ListDatasetAttributes(projectID, datasetID int) ([]DatasetAttribute, error) {
+	attrs, err := do[[]DatasetAttribute](c, apiRequest{
+		op:     "ListDatasetAttributes",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/datasets/%d/attributes", projectID, datasetID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *attrs, nil
+}
+
+// AddDatasetAttribute adds an attribute to a dataset.
+func (c *Client) AddDatasetAttribute(projectID, datasetID int, attr Attribute) (*DatasetAttribute, error) {
+	return do[DatasetAttribute](c, apiRequest{
+		op:     "AddDatasetAttribute",
+		method: http.MethodPost,
+		path:   apiPath("/projects/%d/datasets/%d/attributes", projectID, datasetID),
+		body:   attr,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// UpdateDatasetAttribute replaces the value and unit of one of a dataset's attributes.
+func (c *Client) UpdateDatasetAttribute(projectID, datasetID, attributeID int, attr Attribute) (*DatasetAttribute, error) {
+	return do[DatasetAttribute](c, apiRequest{
+		op:     "UpdateDatasetAttribute",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d/attributes/%d", projectID, datasetID, attributeID),
+		body:   attr,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// RemoveDatasetAttribute removes an attribute from a dataset.
+func (c *Client) RemoveDatasetAttribute(projectID, datasetID, attributeID int) error {
+	return c.send(apiRequest{
+		op:     "RemoveDatasetAttribute",
+		method: http.MethodDelete,
+		path:   apiPath("/projects/%d/datasets/%d/attributes/%d", projectID, datasetID, attributeID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	}, nil)
+}
+
+// SetDatasetTags replaces a dataset's tags, returning the updated dataset. Use
+// AddDatasetTags or RemoveDatasetTags to keep the tags already on the dataset.
+func (c *Client) SetDatasetTags(projectID, datasetID int, tags []Tag) (*DatasetDetails, error) {
+	if tags == nil {
+		tags = []Tag{}
+	}
+
+	return do[DatasetDetails](c, apiRequest{
+		op:     "SetDatasetTags",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/datasets/%d/tags", projectID, datasetID),
+		body:   tagsRequest{Tags: tags},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
+
+// AddDatasetTags adds tags to those already on a dataset, returning the updated dataset.
+// Tags the dataset already has are ignored.
+func (c *Client) AddDatasetTags(projectID, datasetID int, tags ...string) (*DatasetDetails, error) {
+	return c.editDatasetTags("AddDatasetTags", projectID, datasetID, func(existing []Tag) []Tag {
+		return MergeTags(existing, tags...)
+	})
+}
+
+// RemoveDatasetTags removes tags from a dataset, returning the updated dataset. Tags the
+// dataset doesn't have are ignored.
+func (c *Client) RemoveDatasetTags(projectID, datasetID int, tags ...string) (*DatasetDetails, error) {
+	return c.editDatasetTags("RemoveDatasetTags", projectID, datasetID, func(existing []Tag) []Tag {
+		return RemoveTags(existing, tags...)
+	})
+}
+
+// editDatasetTags replaces a dataset's tags with the result of calling edit on its current
+// tags.
+func (c *Client) editDatasetTags(opName string, projectID, datasetID int, edit func(existing []Tag) []Tag) (*DatasetDetails, error) {
+	op := c.startOperation(opName, projectIDAttr(projectID), datasetIDAttr(datasetID))
+	oc := op.client()
+
+	ds, err := oc.GetDatasetDetails(projectID, datasetID)
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	ds, err = oc.SetDatasetTags(projectID, datasetID, edit(ds.Tags))
+	return ds, op.end(err)
+}
+
+// MergeTags returns existing with each of tags added, unless a tag with the same value,
+// ignoring case and surrounding spaces, is already there. Use it to add tags to a
+// CreateOrUpdateDatasetRequest without losing the dataset's current tags.
+func MergeTags(existing []Tag, tags ...string) []Tag {
+	merged := append([]Tag{}, existing...)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !hasTag(merged, tag) {
+			merged = append(merged, Tag{Value: tag})
+		}
+	}
+	return merged
+}
+
+// RemoveTags returns existing without any of tags, ignoring case and surrounding spaces.
+func RemoveTags(existing []Tag, tags ...string) []Tag {
+	remove := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		remove = append(remove, Tag{Value: tag})
+	}
+
+	kept := []Tag{}
+	for _, tag := range existing {
+		if !hasTag(remove, tag.Value) {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+// hasTag returns true if tags has a tag matching value, ignoring case and surrounding spaces.
+func hasTag(tags []Tag, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, tag := range tags {
+		if strings.EqualFold(strings.TrimSpace(tag.Value), value) {
+			return true
+		}
+	}
+	return false
+}
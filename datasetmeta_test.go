@@ -0,0 +1,86 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_DatasetPapersAndAttributes(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5/papers", `{"data": [{"id": 3, "name": "Paper", "doi": "10.1/p"}]}`)
+	srv.handle(http.MethodPost, "/projects/1/datasets/5/papers", `{"data": {"id": 4, "name": "New"}}`)
+	srv.handle(http.MethodPut, "/projects/1/datasets/5/papers/4", `{"data": {"id": 4, "name": "Renamed"}}`)
+	srv.handle(http.MethodDelete, "/projects/1/datasets/5/papers/4", `{}`)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5/attributes", `{"data": [{"id": 7, "name": "instrument", "value": "CT"}]}`)
+	srv.handle(http.MethodPost, "/projects/1/datasets/5/attributes", `{"data": {"id": 8, "name": "temp", "value": 300, "unit": "K"}}`)
+	srv.handle(http.MethodPut, "/projects/1/datasets/5/attributes/8", `{"data": {"id": 8, "name": "temp", "value": 310, "unit": "K"}}`)
+	srv.handle(http.MethodDelete, "/projects/1/datasets/5/attributes/8", `{}`)
+	c := srv.client()
+
+	papers, err := c.ListDatasetPapers(1, 5)
+	if err != nil || len(papers) != 1 || papers[0].ID != 3 || papers[0].DOI != "10.1/p" {
+		t.Errorf("ListDatasetPapers returned %+v, %v", papers, err)
+	}
+
+	if p, err := c.AddDatasetPaper(1, 5, Paper{Name: "New"}); err != nil || p.ID != 4 {
+		t.Errorf("AddDatasetPaper returned %+v, %v", p, err)
+	}
+
+	if p, err := c.UpdateDatasetPaper(1, 5, 4, Paper{Name: "Renamed"}); err != nil || p.Name != "Renamed" {
+		t.Errorf("UpdateDatasetPaper returned %+v, %v", p, err)
+	}
+
+	if err := c.RemoveDatasetPaper(1, 5, 4); err != nil {
+		t.Error(err)
+	}
+
+	attrs, err := c.ListDatasetAttributes(1, 5)
+	if err != nil || len(attrs) != 1 || attrs[0].Name != "instrument" {
+		t.Errorf("ListDatasetAttributes returned %+v, %v", attrs, err)
+	}
+
+	if a, err := c.AddDatasetAttribute(1, 5, Attribute{Name: "temp", Value: 300, Unit: "K"}); err != nil || a.ID != 8 {
+		t.Errorf("AddDatasetAttribute returned %+v, %v", a, err)
+	}
+
+	if a, err := c.UpdateDatasetAttribute(1, 5, 8, Attribute{Name: "temp", Value: 310, Unit: "K"}); err != nil || a.Value != float64(310) {
+		t.Errorf("UpdateDatasetAttribute returned %+v, %v", a, err)
+	}
+
+	if err := c.RemoveDatasetAttribute(1, 5, 8); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_AddAndRemoveDatasetTags(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "tags": [{"value": "CT"}, {"value": "steel"}]}}`)
+
+	var sent tagsRequest
+	srv.handleFunc(http.MethodPut, "/projects/1/datasets/5/tags", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		b, _ := json.Marshal(sent.Tags)
+		writeJSON(w, `{"data": {"id": 5, "tags": `+string(b)+`}}`)
+	})
+	c := srv.client()
+
+	ds, err := c.AddDatasetTags(1, 5, "ct", " alloy ", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(ds.Tags) != "[{CT} {steel} {alloy}]" {
+		t.Errorf("unexpected tags after add %v", ds.Tags)
+	}
+
+	ds, err = c.RemoveDatasetTags(1, 5, "STEEL", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(ds.Tags) != "[{CT}]" {
+		t.Errorf("unexpected tags after remove %v", ds.Tags)
+	}
+}
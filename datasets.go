@@ -0,0 +1,141 @@
+package mcapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDatasetPublished is returned by DeleteDataset when asked to delete a published dataset
+// without the force option.
+var ErrDatasetPublished = errors.New("mcapi: dataset is published")
+
+// DatasetState is where a dataset is in its lifecycle.
+type DatasetState string
+
+const (
+	// DatasetDraft is a dataset that hasn't been published. Its files and metadata can be
+	// changed freely.
+	DatasetDraft DatasetState = "draft"
+
+	// DatasetPublishing is a dataset that has been published, but whose zip file the server
+	// is still building, so it can't be downloaded yet.
+	DatasetPublishing DatasetState = "publishing"
+
+	// DatasetPublished is a dataset that has been published and is publicly visible.
+	DatasetPublished DatasetState = "published"
+)
+
+// DatasetStatus summarizes a dataset's lifecycle, as returned by GetDatasetStatus.
+type DatasetStatus struct {
+	State DatasetState
+
+	// PublishedAt is when the dataset was published. It's zero for drafts.
+	PublishedAt time.Time
+
+	// DOI is the dataset's DOI, if one has been minted. A DOI can be minted for a draft
+	// before it's published.
+	DOI string
+}
+
+// HasDOI returns true if a DOI has been minted for the dataset.
+func (s DatasetStatus) HasDOI() bool {
+	return s.DOI != ""
+}
+
+// String returns the state, noting whether the dataset has a DOI, eg "published (DOI)".
+func (s DatasetStatus) String() string {
+	if s.HasDOI() {
+		return fmt.Sprintf("%s (DOI %s)", s.State, s.DOI)
+	}
+	return string(s.State)
+}
+
+// GetDatasetStatus derives the status of a dataset from the dataset returned by the API. A
+// mcmodel.Dataset doesn't say whether its zip file has been built, so GetDatasetStatus can't
+// tell a dataset that is still publishing from a published one and never returns
+// DatasetPublishing. Use DatasetDetails.Status for that.
+func GetDatasetStatus(ds *mcmodel.Dataset) DatasetStatus {
+	status := DatasetStatus{State: DatasetDraft, PublishedAt: ds.PublishedAt, DOI: ds.DOI}
+	if !ds.PublishedAt.IsZero() {
+		status.State = DatasetPublished
+	}
+	return status
+}
+
+// DeleteDataset deletes a dataset from a project. The project's files are not deleted. A
+// published dataset is only deleted when force is true, in which case it's unpublished
+// first; otherwise an error matching ErrDatasetPublished is returned. If the delete then
+// fails the dataset is left unpublished, and the error says so. Note that deleting a dataset
+// doesn't remove a DOI minted for it.
+func (c *Client) DeleteDataset(projectID, datasetID int, force bool) error {
+	op := c.startOperation("DeleteDataset", projectIDAttr(projectID), datasetIDAttr(datasetID))
+	oc := op.client()
+
+	ds, err := oc.GetDataset(projectID, datasetID)
+	if err != nil {
+		return op.end(err)
+	}
+
+	if GetDatasetStatus(ds).State == DatasetPublished {
+		if !force {
+			return op.end(fmt.Errorf("%w: %q was published %s", ErrDatasetPublished, ds.Name, ds.PublishedAt.Format(time.DateOnly)))
+		}
+
+		if _, err := oc.UnpublishDataset(projectID, datasetID); err != nil {
+			return op.end(err)
+		}
+	}
+
+	err = op.send(apiRequest{
+		method: http.MethodDelete,
+		path:   apiPath("/datasets/%d", datasetID),
+		query:  map[string]string{"project_id": strconv.Itoa(projectID)},
+	}, nil)
+	if err != nil && !ds.PublishedAt.IsZero() {
+		err = fmt.Errorf("dataset %q was unpublished but couldn't be deleted: %w", ds.Name, err)
+	}
+
+	return op.end(err)
+}
+
+// DatasetDetails is a dataset along with the metadata the server returns for it that isn't
+// part of mcmodel.Dataset.
+type DatasetDetails struct {
+	mcmodel.Dataset
+	Funding        string      `json:"funding"`
+	DatasetAuthors []Author    `json:"ds_authors"`
+	Tags           []Tag       `json:"tags"`
+	Papers         []Paper     `json:"papers"`
+	Attributes     []Attribute `json:"attributes"`
+
+	// ZipfileSize is the size of the zip file built when the dataset was published. It's
+	// zero while the zip file is being built, and nil if the server didn't send it.
+	ZipfileSize *int64 `json:"zipfile_size"`
+}
+
+// Status returns the status of the dataset. Unlike GetDatasetStatus, it reports a published
+// dataset whose zip file the server is still building as DatasetPublishing. That is only
+// detected when the server sends the dataset's zip file size.
+func (d *DatasetDetails) Status() DatasetStatus {
+	status := GetDatasetStatus(&d.Dataset)
+	if status.State == DatasetPublished && d.ZipfileSize != nil && *d.ZipfileSize == 0 {
+		status.State = DatasetPublishing
+	}
+	return status
+}
+
+// GetDatasetDetails retrieves a dataset along with its authors, tags, papers and attributes.
+func (c *Client) GetDatasetDetails(projectID, datasetID int) (*DatasetDetails, error) {
+	return do[DatasetDetails](c, apiRequest{
+		op:     "GetDatasetDetails",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/datasets/%d", projectID, datasetID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), datasetIDAttr(datasetID)},
+	})
+}
@@ -0,0 +1,107 @@
+package mcapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+func TestGetDatasetStatus(t *testing.T) {
+	draft := GetDatasetStatus(&mcmodel.Dataset{DOI: "10.1/abc"})
+	if draft.State != DatasetDraft || !draft.HasDOI() || draft.String() != "draft (DOI 10.1/abc)" {
+		t.Errorf("unexpected draft status %+v", draft)
+	}
+
+	published := GetDatasetStatus(&mcmodel.Dataset{PublishedAt: time.Now()})
+	if published.State != DatasetPublished || published.HasDOI() || published.String() != "published" {
+		t.Errorf("unexpected published status %+v", published)
+	}
+}
+
+func TestDatasetDetails_Status(t *testing.T) {
+	publishedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	building, built := int64(0), int64(1024)
+
+	tests := []struct {
+		details  DatasetDetails
+		expected DatasetState
+	}{
+		{DatasetDetails{}, DatasetDraft},
+		{DatasetDetails{Dataset: mcmodel.Dataset{PublishedAt: publishedAt}, ZipfileSize: &building}, DatasetPublishing},
+		{DatasetDetails{Dataset: mcmodel.Dataset{PublishedAt: publishedAt}, ZipfileSize: &built}, DatasetPublished},
+		{DatasetDetails{Dataset: mcmodel.Dataset{PublishedAt: publishedAt}}, DatasetPublished},
+	}
+
+	for _, test := range tests {
+		if got := test.details.Status().State; got != test.expected {
+			t.Errorf("expected %s, got %s for %+v", test.expected, got, test.details)
+		}
+	}
+}
+
+func TestClient_DeleteDataset(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "draft"}}`)
+	srv.handle(http.MethodDelete, "/datasets/5", `{}`)
+
+	if err := srv.client().DeleteDataset(1, 5, false); err != nil {
+		t.Fatal(err)
+	}
+
+	received := srv.received()
+	if last := received[len(received)-1]; last.Method != http.MethodDelete || last.RawQuery != "project_id=1" {
+		t.Errorf("unexpected delete request %+v", last)
+	}
+}
+
+func TestClient_DeletePublishedDataset(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "pub", "published_at": "2024-05-01T00:00:00Z"}}`)
+	srv.handle(http.MethodPut, "/datasets/5/unpublish", `{"data": {"id": 5}}`)
+	srv.handle(http.MethodDelete, "/datasets/5", `{}`)
+	c := srv.client()
+
+	if err := c.DeleteDataset(1, 5, false); !errors.Is(err, ErrDatasetPublished) {
+		t.Fatalf("expected ErrDatasetPublished, got %v", err)
+	}
+
+	for _, req := range srv.received() {
+		if req.Method != http.MethodGet {
+			t.Fatalf("refused delete should not change anything, got %s %s", req.Method, req.Path)
+		}
+	}
+
+	if err := c.DeleteDataset(1, 5, true); err != nil {
+		t.Fatal(err)
+	}
+
+	received := srv.received()
+	if len(received) < 2 || received[len(received)-2].Path != "/datasets/5/unpublish" || received[len(received)-1].Method != http.MethodDelete {
+		t.Errorf("expected unpublish then delete, got %+v", received)
+	}
+}
+
+func TestClient_DeletePublishedDatasetFailsAfterUnpublish(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "pub", "published_at": "2024-05-01T00:00:00Z"}}`)
+	srv.handle(http.MethodPut, "/datasets/5/unpublish", `{"data": {"id": 5}}`)
+	srv.handleFunc(http.MethodDelete, "/datasets/5", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": "server error"}`))
+	})
+
+	err := srv.client().DeleteDataset(1, 5, true)
+	if err == nil || !strings.Contains(err.Error(), "unpublished but couldn't be deleted") {
+		t.Errorf("expected the error to say the dataset was unpublished, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the delete's APIError to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,382 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DatasetSnapshot records a dataset's metadata and files at a point in time. Snapshots are
+// compared with DiffDatasetSnapshots, and can be saved with WriteFile to compare against
+// later, for example before republishing a dataset.
+type DatasetSnapshot struct {
+	// Source describes where the snapshot came from, such as "project 1 dataset 5" or a
+	// local directory.
+	Source  string    `json:"source"`
+	TakenAt time.Time `json:"taken_at"`
+
+	// HasMetadata is false for snapshots of a local directory, which only have files.
+	// Metadata is only compared when both snapshots have it.
+	HasMetadata bool     `json:"has_metadata"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Funding     string   `json:"funding,omitempty"`
+	DOI         string   `json:"doi,omitempty"`
+	Authors     []Author `json:"authors,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Papers      []Paper  `json:"papers,omitempty"`
+
+	// Files are sorted by path.
+	Files []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is a file in a DatasetSnapshot. Paths are relative to the project or local
+// directory root and start with "/".
+type SnapshotFile struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// SnapshotDataset takes a snapshot of a dataset's metadata and the files it currently
+// selects.
+func (c *Client) SnapshotDataset(projectID, datasetID int) (*DatasetSnapshot, error) {
+	op := c.startOperation("SnapshotDataset", projectIDAttr(projectID), datasetIDAttr(datasetID))
+	oc := op.client()
+
+	ds, err := oc.GetDatasetDetails(projectID, datasetID)
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	files, err := oc.listDatasetFiles(projectID, datasetID)
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	snapshot := &DatasetSnapshot{
+		Source:      fmt.Sprintf("project %d dataset %d", projectID, datasetID),
+		TakenAt:     time.Now(),
+		HasMetadata: true,
+		Name:        ds.Name,
+		Description: ds.Description,
+		Summary:     ds.Summary,
+		License:     ds.License,
+		Funding:     ds.Funding,
+		DOI:         ds.DOI,
+		Authors:     ds.DatasetAuthors,
+		Papers:      ds.Papers,
+		Files:       make([]SnapshotFile, 0, len(files)),
+	}
+
+	for _, tag := range ds.Tags {
+		snapshot.Tags = append(snapshot.Tags, tag.Value)
+	}
+
+	for _, f := range files {
+		snapshot.Files = append(snapshot.Files, SnapshotFile{Path: f.Path, Size: int64(f.Size), Checksum: f.Checksum})
+	}
+
+	return snapshot, op.end(nil)
+}
+
+// SnapshotLocalDir takes a snapshot of the files under dir, computing each one's checksum,
+// so that a dataset can be compared with the data it was built from. Partial files left by an
+// interrupted DownloadDataset or MirrorProject are skipped.
+func SnapshotLocalDir(dir string) (*DatasetSnapshot, error) {
+	snapshot := &DatasetSnapshot{Source: dir, TakenAt: time.Now(), Files: []SnapshotFile{}}
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		if strings.HasSuffix(d.Name(), partialSuffix) {
+			// Left behind by an interrupted download, so not really part of the directory.
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		finfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		checksum, err := fileChecksum(filePath)
+		if err != nil {
+			return err
+		}
+
+		snapshot.Files = append(snapshot.Files, SnapshotFile{
+			Path:     path.Clean("/" + filepath.ToSlash(rel)),
+			Size:     finfo.Size(),
+			Checksum: checksum,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snapshot.Files, func(i, j int) bool { return snapshot.Files[i].Path < snapshot.Files[j].Path })
+	return snapshot, nil
+}
+
+// WriteFile saves the snapshot as JSON to filePath.
+func (s *DatasetSnapshot) WriteFile(filePath string) error {
+	return writeJSONFile(filePath, s)
+}
+
+// ReadDatasetSnapshot loads a snapshot saved with WriteFile.
+func ReadDatasetSnapshot(filePath string) (*DatasetSnapshot, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot DatasetSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to read snapshot %s: %w", filePath, err)
+	}
+
+	return &snapshot, nil
+}
+
+// DatasetDiff describes the differences between two dataset snapshots, from Old to New. It
+// marshals to JSON, and String returns a human-readable report.
+type DatasetDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+
+	Fields         []FieldChange  `json:"fields,omitempty"`
+	AuthorsAdded   []Author       `json:"authors_added,omitempty"`
+	AuthorsRemoved []Author       `json:"authors_removed,omitempty"`
+	TagsAdded      []string       `json:"tags_added,omitempty"`
+	TagsRemoved    []string       `json:"tags_removed,omitempty"`
+	PapersAdded    []Paper        `json:"papers_added,omitempty"`
+	PapersRemoved  []Paper        `json:"papers_removed,omitempty"`
+	FilesAdded     []SnapshotFile `json:"files_added,omitempty"`
+	FilesRemoved   []SnapshotFile `json:"files_removed,omitempty"`
+	FilesChanged   []FileChange   `json:"files_changed,omitempty"`
+}
+
+// FieldChange is a metadata field whose value differs between two snapshots.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// FileChange is a file that is in both snapshots with different contents.
+type FileChange struct {
+	Path string       `json:"path"`
+	Old  SnapshotFile `json:"old"`
+	New  SnapshotFile `json:"new"`
+}
+
+// DiffDatasetSnapshots compares two snapshots. Files are compared by checksum when both
+// snapshots have one, and by size otherwise.
+func DiffDatasetSnapshots(oldSnapshot, newSnapshot *DatasetSnapshot) *DatasetDiff {
+	diff := &DatasetDiff{Old: oldSnapshot.Source, New: newSnapshot.Source}
+
+	if oldSnapshot.HasMetadata && newSnapshot.HasMetadata {
+		diff.diffMetadata(oldSnapshot, newSnapshot)
+	}
+
+	diff.diffFiles(oldSnapshot.Files, newSnapshot.Files)
+	return diff
+}
+
+// diffMetadata fills in the metadata differences between a and b.
+func (d *DatasetDiff) diffMetadata(a, b *DatasetSnapshot) {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"name", a.Name, b.Name},
+		{"description", a.Description, b.Description},
+		{"summary", a.Summary, b.Summary},
+		{"license", a.License, b.License},
+		{"funding", a.Funding, b.Funding},
+		{"doi", a.DOI, b.DOI},
+	}
+
+	for _, f := range fields {
+		if f.old != f.new {
+			d.Fields = append(d.Fields, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+
+	d.AuthorsAdded, d.AuthorsRemoved = diffSets(a.Authors, b.Authors)
+	d.TagsAdded, d.TagsRemoved = diffSets(a.Tags, b.Tags)
+	d.PapersAdded, d.PapersRemoved = diffSets(a.Papers, b.Papers)
+}
+
+// diffFiles fills in the files added, removed and changed between a and b.
+func (d *DatasetDiff) diffFiles(a, b []SnapshotFile) {
+	oldFiles := make(map[string]SnapshotFile, len(a))
+	for _, f := range a {
+		oldFiles[f.Path] = f
+	}
+
+	for _, f := range b {
+		old, ok := oldFiles[f.Path]
+		delete(oldFiles, f.Path)
+		switch {
+		case !ok:
+			d.FilesAdded = append(d.FilesAdded, f)
+		case old.Checksum != "" && f.Checksum != "" && old.Checksum != f.Checksum,
+			(old.Checksum == "" || f.Checksum == "") && old.Size != f.Size:
+			d.FilesChanged = append(d.FilesChanged, FileChange{Path: f.Path, Old: old, New: f})
+		}
+	}
+
+	for _, f := range a {
+		if _, ok := oldFiles[f.Path]; ok {
+			d.FilesRemoved = append(d.FilesRemoved, f)
+		}
+	}
+}
+
+// diffSets returns the items in b that aren't in a, and the items in a that aren't in b.
+func diffSets[T comparable](a, b []T) (added, removed []T) {
+	inA := make(map[T]bool, len(a))
+	for _, item := range a {
+		inA[item] = true
+	}
+
+	inB := make(map[T]bool, len(b))
+	for _, item := range b {
+		inB[item] = true
+		if !inA[item] {
+			added = append(added, item)
+		}
+	}
+
+	for _, item := range a {
+		if !inB[item] {
+			removed = append(removed, item)
+		}
+	}
+
+	return added, removed
+}
+
+// Empty returns true if the snapshots are the same.
+func (d *DatasetDiff) Empty() bool {
+	return len(d.Fields) == 0 &&
+		len(d.AuthorsAdded) == 0 && len(d.AuthorsRemoved) == 0 &&
+		len(d.TagsAdded) == 0 && len(d.TagsRemoved) == 0 &&
+		len(d.PapersAdded) == 0 && len(d.PapersRemoved) == 0 &&
+		len(d.FilesAdded) == 0 && len(d.FilesRemoved) == 0 && len(d.FilesChanged) == 0
+}
+
+// String returns a human-readable report of the differences, one per line, in the style of
+// a unified diff: "+" for additions, "-" for removals and "~" for changes.
+func (d *DatasetDiff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", d.Old, d.New)
+	if d.Empty() {
+		sb.WriteString("no differences\n")
+		return sb.String()
+	}
+
+	for _, f := range d.Fields {
+		fmt.Fprintf(&sb, "~ %s: %q -> %q\n", f.Field, f.Old, f.New)
+	}
+
+	for _, a := range d.AuthorsRemoved {
+		fmt.Fprintf(&sb, "- author %s <%s>\n", a.Name, a.Email)
+	}
+	for _, a := range d.AuthorsAdded {
+		fmt.Fprintf(&sb, "+ author %s <%s>\n", a.Name, a.Email)
+	}
+
+	for _, t := range d.TagsRemoved {
+		fmt.Fprintf(&sb, "- tag %s\n", t)
+	}
+	for _, t := range d.TagsAdded {
+		fmt.Fprintf(&sb, "+ tag %s\n", t)
+	}
+
+	for _, p := range d.PapersRemoved {
+		fmt.Fprintf(&sb, "- paper %s\n", p.Name)
+	}
+	for _, p := range d.PapersAdded {
+		fmt.Fprintf(&sb, "+ paper %s\n", p.Name)
+	}
+
+	for _, f := range d.FilesRemoved {
+		fmt.Fprintf(&sb, "- %s (%d bytes)\n", f.Path, f.Size)
+	}
+	for _, f := range d.FilesAdded {
+		fmt.Fprintf(&sb, "+ %s (%d bytes)\n", f.Path, f.Size)
+	}
+	for _, f := range d.FilesChanged {
+		fmt.Fprintf(&sb, "~ %s (%d -> %d bytes)\n", f.Path, f.Old.Size, f.New.Size)
+	}
+
+	return sb.String()
+}
+
+// DiffDatasets compares two datasets in a project, such as a published dataset and the
+// draft that will replace it.
+func (c *Client) DiffDatasets(projectID, oldDatasetID, newDatasetID int) (*DatasetDiff, error) {
+	oldSnapshot, err := c.SnapshotDataset(projectID, oldDatasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	newSnapshot, err := c.SnapshotDataset(projectID, newDatasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffDatasetSnapshots(oldSnapshot, newSnapshot), nil
+}
+
+// DiffDatasetWithLocalDir compares the files in a dataset with the files under dir, which is
+// laid out like the project, as DownloadDataset writes it for a draft dataset or when
+// DatasetDownloadOptions.Tree is set. A published dataset downloaded as a zip file must be
+// unzipped into dir first. Files added or changed locally show up as additions and changes
+// to the dataset.
+func (c *Client) DiffDatasetWithLocalDir(projectID, datasetID int, dir string) (*DatasetDiff, error) {
+	snapshot, err := c.SnapshotDataset(projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := SnapshotLocalDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffDatasetSnapshots(snapshot, local), nil
+}
+
+// DiffDatasetWithSnapshot compares a snapshot saved with DatasetSnapshot.WriteFile against
+// the dataset as it is now.
+func (c *Client) DiffDatasetWithSnapshot(projectID, datasetID int, snapshotPath string) (*DatasetDiff, error) {
+	saved, err := ReadDatasetSnapshot(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := c.SnapshotDataset(projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffDatasetSnapshots(saved, current), nil
+}
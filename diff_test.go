@@ -0,0 +1,130 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffDatasetSnapshots(t *testing.T) {
+	old := &DatasetSnapshot{
+		Source:      "old",
+		HasMetadata: true,
+		Name:        "Tomography",
+		License:     DatasetLicenseAttribution,
+		Authors:     []Author{{Name: "A"}, {Name: "B"}},
+		Tags:        []string{"ct", "steel"},
+		Files: []SnapshotFile{
+			{Path: "/a.h5", Size: 10, Checksum: "aaa"},
+			{Path: "/b.h5", Size: 10, Checksum: "bbb"},
+			{Path: "/c.h5", Size: 10},
+		},
+	}
+	updated := &DatasetSnapshot{
+		Source:      "new",
+		HasMetadata: true,
+		Name:        "Tomography v2",
+		License:     DatasetLicenseAttribution,
+		Authors:     []Author{{Name: "B"}, {Name: "C"}},
+		Tags:        []string{"steel", "ct"},
+		Papers:      []Paper{{Name: "Paper"}},
+		Files: []SnapshotFile{
+			{Path: "/a.h5", Size: 10, Checksum: "aaa"},
+			{Path: "/b.h5", Size: 10, Checksum: "changed"},
+			{Path: "/c.h5", Size: 12, Checksum: "ccc"},
+			{Path: "/d.h5", Size: 5, Checksum: "ddd"},
+		},
+	}
+
+	diff := DiffDatasetSnapshots(old, updated)
+	if len(diff.Fields) != 1 || diff.Fields[0].Field != "name" {
+		t.Errorf("unexpected field changes %+v", diff.Fields)
+	}
+
+	if len(diff.AuthorsAdded) != 1 || diff.AuthorsAdded[0].Name != "C" || len(diff.AuthorsRemoved) != 1 || diff.AuthorsRemoved[0].Name != "A" {
+		t.Errorf("unexpected author changes +%v -%v", diff.AuthorsAdded, diff.AuthorsRemoved)
+	}
+
+	if len(diff.TagsAdded) != 0 || len(diff.TagsRemoved) != 0 {
+		t.Errorf("reordered tags should not be a change: +%v -%v", diff.TagsAdded, diff.TagsRemoved)
+	}
+
+	if len(diff.PapersAdded) != 1 || len(diff.FilesAdded) != 1 || len(diff.FilesChanged) != 2 || len(diff.FilesRemoved) != 0 {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	report := diff.String()
+	for _, line := range []string{`~ name: "Tomography" -> "Tomography v2"`, "- author A <>", "+ paper Paper", "+ /d.h5 (5 bytes)", "~ /c.h5 (10 -> 12 bytes)"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report is missing %q:\n%s", line, report)
+		}
+	}
+
+	b, err := json.Marshal(diff)
+	if err != nil || !strings.Contains(string(b), `"files_added":[{"path":"/d.h5"`) {
+		t.Errorf("unexpected json %s, %v", b, err)
+	}
+
+	if !DiffDatasetSnapshots(old, old).Empty() {
+		t.Error("a snapshot should not differ from itself")
+	}
+}
+
+func TestClient_DiffDatasetWithLocalDir(t *testing.T) {
+	srv := datasetFilesServer(t, "0001-01-01T00:00:00Z")
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "raw"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// a.txt matches the project, raw/new.txt is only local and raw/b.txt is only in the project.
+	// The partial download of raw/b.txt is ignored.
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "raw", "new.txt"), []byte("new"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "raw", "b.txt.11"+partialSuffix), []byte("b"), 0600)
+
+	diff, err := srv.client().DiffDatasetWithLocalDir(1, 5, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Fields) != 0 || len(diff.FilesChanged) != 0 {
+		t.Errorf("expected only file additions and removals, got %+v", diff)
+	}
+
+	if len(diff.FilesAdded) != 1 || diff.FilesAdded[0].Path != "/raw/new.txt" {
+		t.Errorf("unexpected added files %+v", diff.FilesAdded)
+	}
+
+	if len(diff.FilesRemoved) != 1 || diff.FilesRemoved[0].Path != "/raw/b.txt" {
+		t.Errorf("unexpected removed files %+v", diff.FilesRemoved)
+	}
+}
+
+func TestClient_DiffDatasetWithSnapshot(t *testing.T) {
+	srv := datasetFilesServer(t, "0001-01-01T00:00:00Z")
+	c := srv.client()
+
+	snapshot, err := c.SnapshotDataset(1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot.Name = "Old Name"
+	if err := snapshot.WriteFile(snapshotPath); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.handle(http.MethodGet, "/projects/1/datasets/5", `{"data": {"id": 5, "name": "My Data", "tags": [{"value": "new"}], "file_selection": null}}`)
+	diff, err := c.DiffDatasetWithSnapshot(1, 5, snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Fields) != 1 || diff.Fields[0].New != "My Data" || len(diff.TagsAdded) != 1 || len(diff.FilesRemoved) != 2 {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+}
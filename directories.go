@@ -0,0 +1,189 @@
+package mcapi
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListDirectory lists the files and directories directly inside the directory with the given ID.
+func (c *Client) ListDirectory(projectID, directoryID int) ([]mcmodel.File, error) {
+	files, err := do[[]mcmodel.File](c, apiRequest{
+		op:     "ListDirectory",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/directories/%d/list", projectID, directoryID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *files, nil
+}
+
+// ListDirectoryByPath lists the files and directories directly inside the directory at
+// dirPath in the project.
+func (c *Client) ListDirectoryByPath(projectID int, dirPath string) ([]mcmodel.File, error) {
+	dir, err := c.GetFileByPath(projectID, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dir.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dirPath)
+	}
+
+	return c.ListDirectory(projectID, dir.ID)
+}
+
+// WalkFunc is the type of function called by WalkProject for each file and directory. It
+// follows the same rules as fs.WalkDirFunc: returning fs.SkipDir from a directory skips its
+// contents, returning fs.SkipDir from a file skips the rest of the files in its directory,
+// and returning fs.SkipAll stops the walk. Any other error stops the walk and is returned
+// by WalkProject.
+//
+// If listing a directory fails then fn is called a second time for that directory with
+// the error.
+type WalkFunc func(filePath string, file *mcmodel.File, err error) error
+
+// WalkProject walks the project's file tree rooted at root, calling fn for each file and
+// directory, including root. Entries in each directory are visited in lexical order. Like
+// filepath.WalkDir, it lists each directory as it is reached, so large projects can be
+// walked without loading the whole tree.
+func (c *Client) WalkProject(projectID int, root string, fn WalkFunc) error {
+	rootFile, err := c.GetFileByPath(projectID, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = c.walkDir(projectID, root, rootFile, fn)
+	}
+
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkDir calls fn for file and, if it's a directory, recursively walks its contents.
+func (c *Client) walkDir(projectID int, filePath string, file *mcmodel.File, fn WalkFunc) error {
+	if err := fn(filePath, file, nil); err != nil || !file.IsDir() {
+		if errors.Is(err, fs.SkipDir) && file.IsDir() {
+			// Successfully skipped the directory.
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := c.ListDirectory(projectID, file.ID)
+	if err != nil {
+		// Give fn a chance to report or ignore the error.
+		if err := fn(filePath, file, err); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	for i := range entries {
+		entry := &entries[i]
+		if err := c.walkDir(projectID, path.Join(filePath, entry.Name), entry, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FileTree is a file or directory in a project, along with the directory's contents.
+type FileTree struct {
+	File     mcmodel.File
+	Path     string
+	Children []*FileTree
+}
+
+// GetProjectTree returns the whole tree of files and directories under root. It retrieves
+// every file in the project with a few large paged requests rather than one request per
+// directory, which makes it faster than WalkProject for small and medium projects, but it
+// holds the whole project in memory. Only the current version of each file is included.
+func (c *Client) GetProjectTree(projectID int, root string) (*FileTree, error) {
+	files, err := collect(c.IterateFiles(projectID, ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*FileTree, len(files))
+	for _, f := range currentVersions(files) {
+		nodes[f.ID] = &FileTree{File: f}
+	}
+
+	var rootNode *FileTree
+	for _, node := range nodes {
+		if node.File.IsDir() && path.Clean(node.File.Path) == path.Clean(root) {
+			rootNode = node
+		}
+
+		if parent, ok := nodes[node.File.DirectoryID]; ok && node.File.DirectoryID != node.File.ID {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	if rootNode == nil {
+		return nil, fmt.Errorf("directory %s not found in project %d", root, projectID)
+	}
+
+	rootNode.setPaths(path.Clean(root))
+	return rootNode, nil
+}
+
+// currentVersions returns files with only one version of each file, so that no two files
+// have the same path. The current version is kept, or the latest one if none is marked
+// current.
+func currentVersions(files []mcmodel.File) []mcmodel.File {
+	type key struct {
+		directoryID int
+		name        string
+	}
+
+	latest := make(map[key]int, len(files))
+	var kept []mcmodel.File
+	for _, f := range files {
+		k := key{f.DirectoryID, f.Name}
+		i, seen := latest[k]
+		switch {
+		case !seen:
+			latest[k] = len(kept)
+			kept = append(kept, f)
+		case isNewerVersion(f, kept[i]):
+			kept[i] = f
+		}
+	}
+	return kept
+}
+
+// isNewerVersion returns true if f should replace existing as the version of a file to use.
+func isNewerVersion(f, existing mcmodel.File) bool {
+	if f.Current != existing.Current {
+		return f.Current
+	}
+	return f.UpdatedAt.After(existing.UpdatedAt)
+}
+
+// setPaths sets the path of each node under t, and sorts their children by name.
+func (t *FileTree) setPaths(p string) {
+	t.Path = p
+	sort.Slice(t.Children, func(i, j int) bool { return t.Children[i].File.Name < t.Children[j].File.Name })
+	for _, child := range t.Children {
+		child.setPaths(path.Join(p, child.File.Name))
+	}
+}
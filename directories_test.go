@@ -0,0 +1,105 @@
+package mcapi
+
+import (
+	"io/fs"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+// newTreeServer serves a project with the layout:
+//
+//	/
+//	/a.txt
+//	/raw/
+//	/raw/b.dat
+//	/raw/c.dat
+//	/scratch/
+//	/scratch/d.tmp
+func newTreeServer(t *testing.T) *fakeServer {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/files/by_path", `{"data": {"id": 1, "name": "/", "path": "/", "mime_type": "directory"}}`)
+	srv.handle(http.MethodGet, "/projects/1/directories/1/list", `{"data": [
+		{"id": 4, "name": "scratch", "path": "/scratch", "mime_type": "directory", "directory_id": 1},
+		{"id": 2, "name": "a.txt", "mime_type": "text/plain", "directory_id": 1},
+		{"id": 3, "name": "raw", "path": "/raw", "mime_type": "directory", "directory_id": 1}]}`)
+	srv.handle(http.MethodGet, "/projects/1/directories/3/list", `{"data": [
+		{"id": 5, "name": "b.dat", "mime_type": "application/octet-stream", "directory_id": 3},
+		{"id": 6, "name": "c.dat", "mime_type": "application/octet-stream", "directory_id": 3}]}`)
+	srv.handle(http.MethodGet, "/projects/1/directories/4/list", `{"data": [
+		{"id": 7, "name": "d.tmp", "mime_type": "application/octet-stream", "directory_id": 4}]}`)
+	srv.handle(http.MethodGet, "/projects/1/files", `{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory"},
+		{"id": 4, "name": "scratch", "path": "/scratch", "mime_type": "directory", "directory_id": 1},
+		{"id": 2, "name": "a.txt", "mime_type": "text/plain", "directory_id": 1},
+		{"id": 3, "name": "raw", "path": "/raw", "mime_type": "directory", "directory_id": 1},
+		{"id": 5, "name": "b.dat", "mime_type": "application/octet-stream", "directory_id": 3},
+		{"id": 6, "name": "c.dat", "mime_type": "application/octet-stream", "directory_id": 3},
+		{"id": 7, "name": "d.tmp", "mime_type": "application/octet-stream", "directory_id": 4}]}`)
+	return srv
+}
+
+func TestClient_WalkProject(t *testing.T) {
+	srv := newTreeServer(t)
+
+	var visited []string
+	err := srv.client().WalkProject(1, "/", func(filePath string, file *mcmodel.File, err error) error {
+		if err != nil {
+			return err
+		}
+		visited = append(visited, filePath)
+		switch filePath {
+		case "/scratch":
+			return fs.SkipDir
+		case "/raw/b.dat":
+			// Skips the rest of /raw.
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"/", "/a.txt", "/raw", "/raw/b.dat", "/scratch"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v", expected, visited)
+	}
+}
+
+func TestClient_GetProjectTree(t *testing.T) {
+	srv := newTreeServer(t)
+
+	tree, err := srv.client().GetProjectTree(1, "/raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tree.Path != "/raw" || len(tree.Children) != 2 {
+		t.Fatalf("unexpected tree %+v", tree)
+	}
+
+	if tree.Children[0].Path != "/raw/b.dat" || tree.Children[1].Path != "/raw/c.dat" {
+		t.Errorf("unexpected children %s, %s", tree.Children[0].Path, tree.Children[1].Path)
+	}
+}
+
+func TestClient_GetProjectTreeSkipsOldVersions(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/files", `{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory", "current": true},
+		{"id": 2, "name": "a.txt", "mime_type": "text/plain", "directory_id": 1, "current": false},
+		{"id": 3, "name": "a.txt", "mime_type": "text/plain", "directory_id": 1, "current": true},
+		{"id": 4, "name": "a.txt", "mime_type": "text/plain", "directory_id": 1, "current": false}]}`)
+
+	tree, err := srv.client().GetProjectTree(1, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tree.Children) != 1 || tree.Children[0].File.ID != 3 {
+		t.Errorf("expected only the current version of a.txt, got %+v", tree.Children)
+	}
+}
@@ -0,0 +1,171 @@
+package mcapi
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordedRequest is a request received by a fakeServer.
+type recordedRequest struct {
+	Method   string
+	Path     string
+	RawQuery string
+	Body     []byte
+}
+
+// fakeServer is a stand-in for the Materials Commons API. Responses are registered by method
+// and path with handle. Every request is recorded, and requests that don't match a registered
+// route get a 404.
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	requests []recordedRequest
+}
+
+// newFakeServer starts a fakeServer that is shut down when the test completes.
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	s := &fakeServer{routes: make(map[string]http.HandlerFunc)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// handle registers body as the json response for method and path.
+func (s *fakeServer) handle(method, path, body string) {
+	s.handleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, body)
+	})
+}
+
+// handleFunc registers h as the handler for method and path.
+func (s *fakeServer) handleFunc(method, path string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[method+" "+path] = h
+}
+
+// client returns a client that talks to the fake server.
+func (s *fakeServer) client() *Client {
+	return NewClient(&ClientArgs{BaseURL: s.URL, APIKey: "test-key"})
+}
+
+// received returns the requests the server has received.
+func (s *fakeServer) received() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.requests...)
+}
+
+func (s *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	path := r.URL.EscapedPath()
+
+	s.mu.Lock()
+	s.requests = append(s.requests, recordedRequest{
+		Method:   r.Method,
+		Path:     path,
+		RawQuery: r.URL.RawQuery,
+		Body:     body,
+	})
+	h, ok := s.routes[r.Method+" "+path]
+	s.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "no route for ` + r.Method + " " + path + `"}`))
+		return
+	}
+
+	h(w, r)
+}
+
+// writeJSON writes body to w as a JSON response.
+func writeJSON(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(body))
+}
+
+// fakeChunkedUploads implements the chunked upload protocol on a fakeServer, keeping the
+// parts in memory.
+type fakeChunkedUploads struct {
+	mu        sync.Mutex
+	parts     map[int][]byte
+	attempts  map[int]int
+	failFirst map[int]int
+	completed []byte
+	aborted   bool
+}
+
+// handleChunkedUploads registers the chunked upload routes for uploads into the directory
+// with ID dirID in project 1. The first attempt to upload each part number in failFirst is
+// answered with the given status code.
+func (s *fakeServer) handleChunkedUploads(dirID int, partSize int64, failFirst map[int]int) *fakeChunkedUploads {
+	u := &fakeChunkedUploads{
+		parts:     make(map[int][]byte),
+		attempts:  make(map[int]int),
+		failFirst: failFirst,
+	}
+
+	s.handle(http.MethodPost, fmt.Sprintf("/projects/1/files/%d/uploads", dirID),
+		fmt.Sprintf(`{"data": {"id": "u1", "part_size": %d}}`, partSize))
+
+	s.handleFunc(http.MethodDelete, "/projects/1/uploads/u1", func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		u.aborted = true
+		u.mu.Unlock()
+		writeJSON(w, `{}`)
+	})
+
+	s.handleFunc(http.MethodPost, "/projects/1/uploads/u1/complete", func(w http.ResponseWriter, r *http.Request) {
+		var req completeChunkedUploadRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		var contents []byte
+		for i, part := range req.Parts {
+			data, ok := u.parts[part.PartNumber]
+			if !ok || part.PartNumber != i+1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			contents = append(contents, data...)
+		}
+		u.completed = contents
+		writeJSON(w, fmt.Sprintf(`{"data": {"id": 99, "size": %d, "directory_id": %d}}`, len(contents), dirID))
+	})
+
+	for n := 1; n <= 100; n++ {
+		n := n
+		s.handleFunc(http.MethodPut, fmt.Sprintf("/projects/1/uploads/u1/parts/%d", n), func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			u.mu.Lock()
+			u.attempts[n]++
+			if status, ok := u.failFirst[n]; ok && u.attempts[n] == 1 {
+				u.mu.Unlock()
+				w.WriteHeader(status)
+				return
+			}
+			u.parts[n] = body
+			u.mu.Unlock()
+
+			sum := md5.Sum(body)
+			writeJSON(w, fmt.Sprintf(`{"data": {"part_number": %d, "checksum": "%s"}}`, n, hex.EncodeToString(sum[:])))
+		})
+	}
+
+	return u
+}
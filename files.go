@@ -0,0 +1,219 @@
+package mcapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDirectoryNotEmpty is returned by DeleteDirectory when asked to delete a directory that has
+// contents without the recursive option.
+var ErrDirectoryNotEmpty = errors.New("mcapi: directory not empty")
+
+// FileRef refers to a file or directory in a project either by its ID or by its path. Create
+// one with IDRef or PathRef.
+type FileRef struct {
+	ID   int
+	Path string
+}
+
+// IDRef refers to a file or directory by its ID.
+func IDRef(id int) FileRef {
+	return FileRef{ID: id}
+}
+
+// PathRef refers to a file or directory by its path in the project, such as /raw/run1.h5.
+func PathRef(path string) FileRef {
+	return FileRef{Path: path}
+}
+
+// String returns the ID or path the reference was created with.
+func (ref FileRef) String() string {
+	if ref.Path != "" {
+		return ref.Path
+	}
+	return strconv.Itoa(ref.ID)
+}
+
+// resolve returns the ID of the file ref refers to, looking it up by path if needed. When
+// wantDir is true the ref must be a directory, otherwise it must be a file. The check can
+// only be made for refs created with PathRef; for IDs the server checks instead.
+func (c *Client) resolve(projectID int, ref FileRef, wantDir bool) (int, error) {
+	if ref.Path == "" {
+		return ref.ID, nil
+	}
+
+	file, err := c.GetFileByPath(projectID, ref.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case wantDir && !file.IsDir():
+		return 0, fmt.Errorf("%s is not a directory", ref.Path)
+	case !wantDir && file.IsDir():
+		return 0, fmt.Errorf("%s is a directory", ref.Path)
+	}
+
+	return file.ID, nil
+}
+
+// moveRequest is the body for calls that place a file or directory in another directory.
+type moveRequest struct {
+	ProjectID   int `json:"project_id"`
+	DirectoryID int `json:"directory_id"`
+}
+
+// renameRequest is the body for calls that rename a file or directory.
+type renameRequest struct {
+	ProjectID int    `json:"project_id"`
+	Name      string `json:"name"`
+}
+
+// MoveFile moves a file into the directory toDir, returning the moved file.
+func (c *Client) MoveFile(projectID int, file, toDir FileRef) (*mcmodel.File, error) {
+	return c.moveFileOrDir("MoveFile", "/files/%d/move", projectID, file, toDir, false)
+}
+
+// CopyFile copies a file into the directory toDir, returning the new copy.
+func (c *Client) CopyFile(projectID int, file, toDir FileRef) (*mcmodel.File, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	dirID, err := c.resolve(projectID, toDir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[mcmodel.File](c, apiRequest{
+		op:     "CopyFile",
+		method: http.MethodPost,
+		path:   apiPath("/files/%d/copy", fileID),
+		body:   moveRequest{ProjectID: projectID, DirectoryID: dirID},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+}
+
+// RenameFile renames a file, leaving it in the same directory, and returns the renamed file.
+func (c *Client) RenameFile(projectID int, file FileRef, name string) (*mcmodel.File, error) {
+	return c.renameFileOrDir("RenameFile", "/files/%d/rename", projectID, file, name, false)
+}
+
+// DeleteFile deletes a file from a project.
+func (c *Client) DeleteFile(projectID int, file FileRef) error {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return err
+	}
+
+	return c.send(apiRequest{
+		op:     "DeleteFile",
+		method: http.MethodDelete,
+		path:   apiPath("/files/%d", fileID),
+		query:  map[string]string{"project_id": strconv.Itoa(projectID)},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	}, nil)
+}
+
+// DownloadFile writes the contents of a file to w, returning the number of bytes written.
+func (c *Client) DownloadFile(projectID int, file FileRef, w io.Writer) (int64, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.download(apiRequest{
+		op:     "DownloadFile",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/files/%d/download", projectID, fileID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	}, w)
+}
+
+// MoveDirectory moves a directory, along with everything in it, into the directory toDir. It
+// returns the moved directory.
+func (c *Client) MoveDirectory(projectID int, dir, toDir FileRef) (*mcmodel.File, error) {
+	return c.moveFileOrDir("MoveDirectory", "/directories/%d/move", projectID, dir, toDir, true)
+}
+
+// RenameDirectory renames a directory, leaving it in the same parent directory, and returns
+// the renamed directory.
+func (c *Client) RenameDirectory(projectID int, dir FileRef, name string) (*mcmodel.File, error) {
+	return c.renameFileOrDir("RenameDirectory", "/directories/%d/rename", projectID, dir, name, true)
+}
+
+// DeleteDirectory deletes a directory from a project. Unless recursive is true, the directory
+// must be empty or an error matching ErrDirectoryNotEmpty is returned. With recursive set,
+// everything in the directory is deleted along with it.
+func (c *Client) DeleteDirectory(projectID int, dir FileRef, recursive bool) error {
+	dirID, err := c.resolve(projectID, dir, true)
+	if err != nil {
+		return err
+	}
+
+	if !recursive {
+		entries, err := c.ListDirectory(projectID, dirID)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) != 0 {
+			return fmt.Errorf("%w: %s has %d entries", ErrDirectoryNotEmpty, dir, len(entries))
+		}
+	}
+
+	return c.send(apiRequest{
+		op:     "DeleteDirectory",
+		method: http.MethodDelete,
+		path:   apiPath("/directories/%d", dirID),
+		query: map[string]string{
+			"project_id": strconv.Itoa(projectID),
+			"recursive":  strconv.FormatBool(recursive),
+		},
+		attrs: []attribute.KeyValue{projectIDAttr(projectID)},
+	}, nil)
+}
+
+// moveFileOrDir does the work for MoveFile and MoveDirectory.
+func (c *Client) moveFileOrDir(opName, pathFormat string, projectID int, ref, toDir FileRef, isDir bool) (*mcmodel.File, error) {
+	id, err := c.resolve(projectID, ref, isDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirID, err := c.resolve(projectID, toDir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[mcmodel.File](c, apiRequest{
+		op:     opName,
+		method: http.MethodPut,
+		path:   apiPath(pathFormat, id),
+		body:   moveRequest{ProjectID: projectID, DirectoryID: dirID},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+}
+
+// renameFileOrDir does the work for RenameFile and RenameDirectory.
+func (c *Client) renameFileOrDir(opName, pathFormat string, projectID int, ref FileRef, name string, isDir bool) (*mcmodel.File, error) {
+	id, err := c.resolve(projectID, ref, isDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[mcmodel.File](c, apiRequest{
+		op:     opName,
+		method: http.MethodPut,
+		path:   apiPath(pathFormat, id),
+		body:   renameRequest{ProjectID: projectID, Name: name},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+}
@@ -0,0 +1,106 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// handleByPath serves /files/by_path from a map of project path to file json.
+func handleByPath(srv *fakeServer, files map[string]string) {
+	srv.handleFunc(http.MethodPost, "/files/by_path", func(w http.ResponseWriter, r *http.Request) {
+		var req projectPathRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		file, ok := files[req.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "not found"}`))
+			return
+		}
+		writeJSON(w, `{"data": `+file+`}`)
+	})
+}
+
+func TestClient_MoveFileByPath(t *testing.T) {
+	srv := newFakeServer(t)
+	handleByPath(srv, map[string]string{
+		"/raw/a.txt": `{"id": 10, "name": "a.txt", "mime_type": "text/plain"}`,
+		"/archive":   `{"id": 20, "name": "archive", "path": "/archive", "mime_type": "directory"}`,
+	})
+	srv.handle(http.MethodPut, "/files/10/move", `{"data": {"id": 10, "name": "a.txt", "directory_id": 20}}`)
+
+	file, err := srv.client().MoveFile(1, PathRef("/raw/a.txt"), PathRef("/archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.DirectoryID != 20 {
+		t.Errorf("expected file to be in directory 20, got %d", file.DirectoryID)
+	}
+
+	received := srv.received()
+	var body moveRequest
+	if err := json.Unmarshal(received[len(received)-1].Body, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.ProjectID != 1 || body.DirectoryID != 20 {
+		t.Errorf("unexpected move request %+v", body)
+	}
+
+	if _, err := srv.client().MoveFile(1, PathRef("/archive"), PathRef("/raw/a.txt")); err == nil {
+		t.Error("expected error moving a directory with MoveFile")
+	}
+}
+
+func TestClient_RenameAndCopyByID(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPut, "/files/10/rename", `{"data": {"id": 10, "name": "b.txt"}}`)
+	srv.handle(http.MethodPost, "/files/10/copy", `{"data": {"id": 11, "name": "b.txt"}}`)
+	srv.handle(http.MethodPut, "/directories/20/rename", `{"data": {"id": 20, "name": "old"}}`)
+	srv.handle(http.MethodPut, "/directories/20/move", `{"data": {"id": 20, "directory_id": 30}}`)
+	c := srv.client()
+
+	if file, err := c.RenameFile(1, IDRef(10), "b.txt"); err != nil || file.Name != "b.txt" {
+		t.Errorf("RenameFile returned %+v, %v", file, err)
+	}
+
+	if file, err := c.CopyFile(1, IDRef(10), IDRef(30)); err != nil || file.ID != 11 {
+		t.Errorf("CopyFile returned %+v, %v", file, err)
+	}
+
+	if dir, err := c.RenameDirectory(1, IDRef(20), "old"); err != nil || dir.Name != "old" {
+		t.Errorf("RenameDirectory returned %+v, %v", dir, err)
+	}
+
+	if dir, err := c.MoveDirectory(1, IDRef(20), IDRef(30)); err != nil || dir.DirectoryID != 30 {
+		t.Errorf("MoveDirectory returned %+v, %v", dir, err)
+	}
+}
+
+func TestClient_DeleteDirectory(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/directories/20/list", `{"data": [{"id": 21, "name": "x"}]}`)
+	srv.handle(http.MethodDelete, "/directories/20", `{}`)
+	srv.handle(http.MethodDelete, "/files/10", `{}`)
+	c := srv.client()
+
+	if err := c.DeleteDirectory(1, IDRef(20), false); !errors.Is(err, ErrDirectoryNotEmpty) {
+		t.Errorf("expected ErrDirectoryNotEmpty, got %v", err)
+	}
+
+	if err := c.DeleteDirectory(1, IDRef(20), true); err != nil {
+		t.Fatal(err)
+	}
+
+	received := srv.received()
+	if q := received[len(received)-1].RawQuery; q != "project_id=1&recursive=true" {
+		t.Errorf("unexpected delete query %q", q)
+	}
+
+	if err := c.DeleteFile(1, IDRef(10)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,193 @@
+package mcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrReadOnly is returned by calls that are vetoed by the ReadOnly hook.
+var ErrReadOnly = errors.New("mcapi: client is read-only")
+
+// Call describes a request made by one of the client's methods. It is passed to the
+// hooks registered with OnBeforeRequest and OnAfterResponse.
+type Call struct {
+	// Operation is the name of the client method making the call, such as CreateEntity.
+	Operation string
+
+	// Method is the HTTP method, and URL the full URL of the request.
+	Method string
+	URL    string
+
+	// Request is the typed request body, such as a CreateEntityRequest. It is nil for
+	// calls without a JSON body. A before request hook can replace it to change what
+	// is sent.
+	Request any
+
+	// Header holds the request headers. Before request hooks can add or change headers.
+	Header http.Header
+
+	// Result is a pointer to the typed result the response is decoded into, such as
+	// *mcmodel.Entity. It is only set for after response hooks.
+	Result any
+
+	// StatusCode is the HTTP status of the response. It is only set for after response
+	// hooks, and is 0 if no response was received.
+	StatusCode int
+
+	// Context is the context of the call.
+	Context context.Context
+
+	// readOnly is set for calls that only look data up even though their method, such
+	// as POST, could change data.
+	readOnly bool
+}
+
+// IsMutation returns true if the call can change data on the server.
+func (call *Call) IsMutation() bool {
+	if call.readOnly {
+		return false
+	}
+
+	switch call.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// BeforeRequestHook is called before a request is sent. It can modify call.Request and
+// call.Header. Returning an error vetoes the request, and the error is returned by the
+// client method. The hook is called again if the request is retried.
+type BeforeRequestHook func(call *Call) error
+
+// AfterResponseHook is called once a call has completed, after any retries. err is the
+// error the client method will return.
+type AfterResponseHook func(call *Call, err error)
+
+// ReadOnly is a BeforeRequestHook that vetoes any call that can change data on the server.
+// The vetoed call returns an error wrapping ErrReadOnly.
+func ReadOnly(call *Call) error {
+	if call.IsMutation() {
+		return fmt.Errorf("%w: %s %s not allowed", ErrReadOnly, call.Operation, call.Method)
+	}
+	return nil
+}
+
+// hookChain holds the registered hooks. It is shared between a client and the copies
+// made by WithContext.
+type hookChain struct {
+	mu     sync.RWMutex
+	before []BeforeRequestHook
+	after  []AfterResponseHook
+}
+
+// OnBeforeRequest registers a hook that is called before every request the client
+// makes. Hooks are called in the order they were registered, and the first hook to
+// return an error stops the request.
+func (c *Client) OnBeforeRequest(hook BeforeRequestHook) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.before = append(c.hooks.before, hook)
+}
+
+// OnAfterResponse registers a hook that is called after every call the client makes
+// completes. Hooks are called in the order they were registered.
+func (c *Client) OnAfterResponse(hook AfterResponseHook) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.after = append(c.hooks.after, hook)
+}
+
+// operationKey is the context key for the name of the operation making a request.
+type operationKey struct{}
+
+// operationName returns the name of the operation stored in ctx by startOperation.
+func operationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// readOnlyKey is the context key marking a request that only looks data up.
+type readOnlyKey struct{}
+
+// newCall creates the Call describing req.
+func newCall(req *resty.Request) *Call {
+	return &Call{
+		Operation: operationName(req.Context()),
+		Method:    req.Method,
+		URL:       req.URL,
+		Request:   req.Body,
+		Header:    req.Header,
+		Context:   req.Context(),
+		readOnly:  req.Context().Value(readOnlyKey{}) == true,
+	}
+}
+
+// runBeforeHooks is a resty request middleware that runs the before request hooks,
+// applying any changes they make to the request body.
+func (c *Client) runBeforeHooks(_ *resty.Client, req *resty.Request) error {
+	c.hooks.mu.RLock()
+	hooks := c.hooks.before
+	c.hooks.mu.RUnlock()
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	call := newCall(req)
+	for _, hook := range hooks {
+		if err := hook(call); err != nil {
+			return err
+		}
+	}
+
+	req.Body = call.Request
+	return nil
+}
+
+// runAfterHooksOnSuccess is a resty success hook that runs the after response hooks.
+func (c *Client) runAfterHooksOnSuccess(_ *resty.Client, resp *resty.Response) {
+	c.runAfterHooks(resp.Request, resp, checkError(resp, nil))
+}
+
+// runAfterHooksOnError is a resty error hook that runs the after response hooks.
+func (c *Client) runAfterHooksOnError(req *resty.Request, err error) {
+	var resp *resty.Response
+	var respErr *resty.ResponseError
+	if errors.As(err, &respErr) {
+		resp = respErr.Response
+		err = respErr.Err
+	}
+	c.runAfterHooks(req, resp, err)
+}
+
+// runAfterHooks calls the after response hooks for req.
+func (c *Client) runAfterHooks(req *resty.Request, resp *resty.Response, err error) {
+	c.hooks.mu.RLock()
+	hooks := c.hooks.after
+	c.hooks.mu.RUnlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	call := newCall(req)
+	call.Result = req.Result
+	if dw, ok := req.Result.(*DataWrapper); ok {
+		call.Result = dw.Data
+	}
+
+	if resp != nil {
+		call.StatusCode = resp.StatusCode()
+	}
+
+	for _, hook := range hooks {
+		hook(call, err)
+	}
+}
@@ -0,0 +1,116 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+func TestClient_BeforeRequestHookModifiesRequest(t *testing.T) {
+	var gotTenant, gotName string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get("X-Tenant")
+		var req CreateProjectRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotName = req.Name
+		writeJSON(w, `{"data": {"id": 1, "name": "`+req.Name+`"}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL})
+	c.OnBeforeRequest(func(call *Call) error {
+		if call.Operation != "CreateProject" {
+			t.Errorf("unexpected operation %q", call.Operation)
+		}
+		call.Header.Set("X-Tenant", "lab1")
+		req := call.Request.(CreateProjectRequest)
+		req.Name = "lab1-" + req.Name
+		call.Request = req
+		return nil
+	})
+
+	var afterCall *Call
+	c.OnAfterResponse(func(call *Call, err error) {
+		if err != nil {
+			t.Errorf("unexpected error in after hook: %s", err)
+		}
+		afterCall = call
+	})
+
+	if _, err := c.CreateProject(CreateProjectRequest{Name: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotTenant != "lab1" {
+		t.Errorf("expected X-Tenant header lab1, got %q", gotTenant)
+	}
+
+	if gotName != "lab1-p1" {
+		t.Errorf("expected modified project name, got %q", gotName)
+	}
+
+	if afterCall == nil {
+		t.Fatal("after response hook not called")
+	}
+
+	proj, ok := afterCall.Result.(*mcmodel.Project)
+	if !ok || proj.Name != "lab1-p1" {
+		t.Errorf("expected typed project result, got %#v", afterCall.Result)
+	}
+
+	if afterCall.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", afterCall.StatusCode)
+	}
+}
+
+func TestClient_ReadOnlyHookVetoesMutations(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		writeJSON(w, `{"data": {"id": 1}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL})
+	c.OnBeforeRequest(ReadOnly)
+
+	var afterErr error
+	c.OnAfterResponse(func(call *Call, err error) {
+		afterErr = err
+	})
+
+	if err := c.DeleteProject(1); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+
+	if !errors.Is(afterErr, ErrReadOnly) {
+		t.Errorf("expected after hook to see ErrReadOnly, got %v", afterErr)
+	}
+
+	if requests != 0 {
+		t.Errorf("vetoed request reached the server")
+	}
+
+	if _, err := c.GetProject(1); err != nil {
+		t.Errorf("read-only client should allow GetProject: %s", err)
+	}
+}
+
+func TestClient_ReadOnlyHookAllowsLookups(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPost, "/files/by_path", `{"data": {"id": 10, "name": "a.txt"}}`)
+	c := srv.client()
+	c.OnBeforeRequest(ReadOnly)
+
+	if _, err := c.GetFileByPath(1, "/a.txt"); err != nil {
+		t.Errorf("read-only client should allow GetFileByPath: %s", err)
+	}
+
+	if _, err := c.CreateDirectoryByPath(1, "/raw"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
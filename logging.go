@@ -0,0 +1,226 @@
+package mcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/materials-commons/config"
+)
+
+// maxLoggedBodySize is the number of bytes of a request or response body that will be
+// logged at debug level. Anything beyond this is truncated.
+const maxLoggedBodySize = 4096
+
+// redacted replaces the value of sensitive headers when they are logged.
+const redacted = "REDACTED"
+
+// discardHandler is a slog.Handler that drops everything. It's used when the client
+// was not given a logger.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// SetLogger sets the logger the client writes request records to. Passing nil turns
+// logging off. It's safe to call while requests are in progress, and it changes the
+// logger for copies of the client made with WithContext too.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(discardHandler{})
+	}
+	c.logger.Store(logger)
+}
+
+// SetDebug turns on debug level logging, which includes request and response bodies.
+// If no logger has been set then turning debug on will log to stdout until debug is
+// turned off again. Turning debug off restores the level from the mclogging config setting.
+func (c *Client) SetDebug(on bool) {
+	if !on {
+		c.logLevel.Set(configLogLevel())
+		if stdout := c.debugLogger.Swap(nil); stdout != nil {
+			c.logger.CompareAndSwap(stdout, slog.New(discardHandler{}))
+		}
+		return
+	}
+
+	if current := c.logger.Load(); current != nil {
+		if _, ok := current.Handler().(discardHandler); ok {
+			stdout := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			if c.logger.CompareAndSwap(current, stdout) {
+				c.debugLogger.Store(stdout)
+			}
+		}
+	}
+	c.logLevel.Set(slog.LevelDebug)
+}
+
+// restyLogger passes the messages resty logs, such as retry attempts, to the client's logger
+// instead of stderr. They repeat what the request records say, so they're only written at
+// debug level.
+type restyLogger struct {
+	c *Client
+}
+
+func (l restyLogger) Errorf(format string, v ...any) { l.log("error", format, v...) }
+func (l restyLogger) Warnf(format string, v ...any)  { l.log("warn", format, v...) }
+func (l restyLogger) Debugf(format string, v ...any) { l.log("debug", format, v...) }
+
+// log writes a resty message at debug level, recording resty's own level.
+func (l restyLogger) log(restyLevel, format string, v ...any) {
+	ctx := context.Background()
+	if !l.c.logEnabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	msg := strings.TrimSpace(fmt.Sprintf(format, v...))
+	l.c.logger.Load().LogAttrs(ctx, slog.LevelDebug, "resty", slog.String("resty_level", restyLevel), slog.String("message", msg))
+}
+
+// configLogLevel returns the log level from the mclogging config setting. It understands
+// debug, info, warn and error, and defaults to info if the setting is missing or invalid.
+func configLogLevel() slog.Level {
+	if err := ensureConfig(); err != nil {
+		return slog.LevelInfo
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.GetString("mclogging"))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// logEnabled returns true if a record at level should be written.
+func (c *Client) logEnabled(ctx context.Context, level slog.Level) bool {
+	return level >= c.logLevel.Level() && c.logger.Load().Enabled(ctx, level)
+}
+
+// logResponse is a resty success hook. It is called once per request after any retries,
+// and writes a record describing the request. Requests that received an error status
+// are logged at warn level.
+func (c *Client) logResponse(_ *resty.Client, resp *resty.Response) {
+	level := slog.LevelInfo
+	if resp.IsError() {
+		level = slog.LevelWarn
+	}
+	c.logRequest(resp.Request, resp, level, nil)
+}
+
+// logError is a resty error hook. It's called when a request fails without a usable
+// response, such as a connection error or a timeout.
+func (c *Client) logError(req *resty.Request, err error) {
+	var resp *resty.Response
+	var respErr *resty.ResponseError
+	if errors.As(err, &respErr) {
+		resp = respErr.Response
+	}
+	c.logRequest(req, resp, slog.LevelError, err)
+}
+
+// logRequest writes the record for a completed request. resp and err may be nil.
+func (c *Client) logRequest(req *resty.Request, resp *resty.Response, level slog.Level, err error) {
+	ctx := req.Context()
+	if !c.logEnabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", requestPath(req)),
+		slog.Int("retries", max(req.Attempt-1, 0)),
+	}
+
+	if req.RawRequest != nil && req.RawRequest.ContentLength > 0 {
+		attrs = append(attrs, slog.Int64("bytes_sent", req.RawRequest.ContentLength))
+	}
+
+	if resp != nil {
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode()),
+			slog.Duration("duration", resp.Time()),
+			slog.Int64("bytes", resp.Size()))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if c.logEnabled(ctx, slog.LevelDebug) {
+		headers := req.Header
+		if req.RawRequest != nil {
+			headers = req.RawRequest.Header
+		}
+		attrs = append(attrs,
+			slog.Any("headers", redactHeaders(headers)),
+			slog.String("request_body", truncateBody(requestBody(req))))
+		if resp != nil {
+			attrs = append(attrs, slog.String("response_body", truncateBody(resp.Body())))
+		}
+	}
+
+	c.logger.Load().LogAttrs(ctx, level, "mcapi request", attrs...)
+}
+
+// requestPath returns the URL path for the request, falling back to the full URL if the
+// underlying http request was never built.
+func requestPath(req *resty.Request) string {
+	if req.RawRequest != nil && req.RawRequest.URL != nil {
+		return req.RawRequest.URL.Path
+	}
+	return req.URL
+}
+
+// redactHeaders returns a copy of the headers as a map suitable for logging, with the
+// Authorization header value replaced.
+func redactHeaders(headers map[string][]string) map[string]string {
+	logged := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if strings.EqualFold(name, "Authorization") {
+			logged[name] = redacted
+			continue
+		}
+		logged[name] = strings.Join(values, ", ")
+	}
+	return logged
+}
+
+// requestBody returns the body of the request as bytes. Bodies that are streamed (such as
+// file uploads) are not read and are reported as such.
+func requestBody(req *resty.Request) []byte {
+	switch body := req.Body.(type) {
+	case nil:
+		if req.RawRequest != nil && strings.HasPrefix(req.RawRequest.Header.Get("Content-Type"), "multipart/") {
+			return []byte("<multipart body>")
+		}
+		return nil
+	case []byte:
+		return body
+	case string:
+		return []byte(body)
+	case io.Reader:
+		return []byte("<streamed body>")
+	default:
+		b, err := json.Marshal(body)
+		if err != nil {
+			return []byte(fmt.Sprintf("<unable to marshal body: %s>", err))
+		}
+		return b
+	}
+}
+
+// truncateBody returns body as a string, truncated to maxLoggedBodySize bytes.
+func truncateBody(body []byte) string {
+	if len(body) <= maxLoggedBodySize {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (truncated %d bytes)", body[:maxLoggedBodySize], len(body)-maxLoggedBodySize)
+}
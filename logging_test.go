@@ -0,0 +1,158 @@
+package mcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newLoggingTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *bytes.Buffer) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, APIKey: "secret-token", Logger: logger})
+	return c, &buf
+}
+
+func TestClient_LogsRequest(t *testing.T) {
+	c, buf := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 1, "name": "p1"}}`)
+	})
+	c.logLevel.Set(slog.LevelInfo)
+
+	if _, err := c.GetProject(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("unable to decode log record %q: %s", buf.String(), err)
+	}
+
+	if record["method"] != "GET" || record["path"] != "/projects/1" || record["status"] != float64(200) {
+		t.Errorf("unexpected log record: %v", record)
+	}
+
+	if _, ok := record["request_body"]; ok {
+		t.Errorf("bodies should only be logged at debug level: %v", record)
+	}
+}
+
+func TestClient_DebugRedactsAuthorization(t *testing.T) {
+	c, buf := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 1, "name": "`+strings.Repeat("x", 2*maxLoggedBodySize)+`"}}`)
+	})
+	c.SetDebug(true)
+
+	if _, err := c.CreateProject(CreateProjectRequest{Name: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "secret-token") {
+		t.Errorf("log output contains the API key: %s", out)
+	}
+
+	if !strings.Contains(out, redacted) {
+		t.Errorf("expected Authorization header to be redacted: %s", out)
+	}
+
+	if !strings.Contains(out, "truncated") {
+		t.Errorf("expected large response body to be truncated: %s", out)
+	}
+}
+
+func TestClient_LogsErrorStatusAsWarning(t *testing.T) {
+	c, buf := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "not found"}`))
+	})
+
+	if _, err := c.GetProject(1); err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+
+	if !strings.Contains(buf.String(), `"level":"WARN"`) {
+		t.Errorf("expected warning record, got %s", buf.String())
+	}
+}
+
+func TestClient_SetLoggerWhileRequestsRun(t *testing.T) {
+	c, _ := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 1, "name": "p1"}}`)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.WithContext(context.Background()).GetProject(1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	c.SetDebug(true)
+	c.SetDebug(false)
+	c.SetLogger(nil)
+	wg.Wait()
+}
+
+func TestClient_SetDebugOffRemovesStdoutLogger(t *testing.T) {
+	c := NewClient(&ClientArgs{BaseURL: "http://localhost"})
+
+	c.SetDebug(true)
+	if _, ok := c.logger.Load().Handler().(*slog.TextHandler); !ok {
+		t.Fatalf("expected debug to log to stdout, handler is %T", c.logger.Load().Handler())
+	}
+
+	c.SetDebug(false)
+	if _, ok := c.logger.Load().Handler().(discardHandler); !ok {
+		t.Errorf("expected turning debug off to stop logging, handler is %T", c.logger.Load().Handler())
+	}
+}
+
+func TestClient_SetDebugOffKeepsOwnLogger(t *testing.T) {
+	c, _ := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	logger := c.logger.Load()
+
+	c.SetDebug(true)
+	c.SetDebug(false)
+	if c.logger.Load() != logger {
+		t.Error("turning debug off should keep the client's own logger")
+	}
+}
+
+func TestRestyLogger(t *testing.T) {
+	c, buf := newLoggingTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	l := restyLogger{c}
+
+	c.logLevel.Set(slog.LevelInfo)
+	l.Errorf("GET %q: connection refused\n", "http://localhost")
+	if buf.Len() != 0 {
+		t.Errorf("resty messages should only be logged at debug level: %s", buf.String())
+	}
+
+	c.logLevel.Set(slog.LevelDebug)
+	l.Warnf("Attempt %d", 2)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("unable to decode log record %q: %s", buf.String(), err)
+	}
+
+	if record["msg"] != "resty" || record["resty_level"] != "warn" || record["message"] != "Attempt 2" {
+		t.Errorf("unexpected log record: %v", record)
+	}
+}
@@ -0,0 +1,415 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MirrorOptions control MirrorProject.
+type MirrorOptions struct {
+	// Concurrency is the number of files downloaded at once. It defaults to 4.
+	Concurrency int
+
+	// VerifyChecksums compares the checksum of files already on disk with the project's
+	// copy before skipping them. Otherwise files whose size matches are skipped.
+	VerifyChecksums bool
+
+	// SkipMetadata turns off the JSON export of the project's metadata.
+	SkipMetadata bool
+}
+
+// MirrorResult reports what MirrorProject did. Paths are project paths.
+type MirrorResult struct {
+	// Downloaded are the files that were downloaded, including ones resumed from an
+	// earlier, interrupted, mirror.
+	Downloaded []string
+
+	// Skipped are the files that were already on disk and unchanged.
+	Skipped []string
+
+	// Failed are the files that couldn't be downloaded, and why.
+	Failed map[string]error
+
+	// Bytes is the number of bytes downloaded.
+	Bytes int64
+}
+
+const (
+	// mirrorFilesDir and mirrorMetadataDir are the directories under localDir that
+	// MirrorProject writes the project's files and metadata to.
+	mirrorFilesDir    = "files"
+	mirrorMetadataDir = "metadata"
+
+	// partialSuffix is added to the name of a file while it's being downloaded.
+	partialSuffix = ".mcpart"
+
+	defaultMirrorConcurrency = 4
+)
+
+// MirrorProject downloads every file in a project into localDir/files, recreating the
+// project's directory tree, and writes the project, its experiments, activities, entities,
+// entity states and datasets as JSON files in localDir/metadata.
+//
+// MirrorProject can be run again on the same localDir to bring it up to date or to finish an
+// interrupted mirror. Files already on disk are skipped, and partly downloaded files are
+// resumed where they left off if the server supports range requests.
+//
+// Failing to download a file doesn't stop the mirror. The failures are listed in the result,
+// and the returned error joins them.
+func (c *Client) MirrorProject(projectID int, localDir string, opts MirrorOptions) (*MirrorResult, error) {
+	op := c.startOperation("MirrorProject", projectIDAttr(projectID))
+	result, err := op.client().mirrorProject(projectID, localDir, opts)
+	return result, op.end(err)
+}
+
+// mirrorProject does the work for MirrorProject.
+func (c *Client) mirrorProject(projectID int, localDir string, opts MirrorOptions) (*MirrorResult, error) {
+	if !opts.SkipMetadata {
+		if err := c.exportProjectMetadata(projectID, filepath.Join(localDir, mirrorMetadataDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	tree, err := c.GetProjectTree(projectID, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	filesDir := filepath.Join(localDir, mirrorFilesDir)
+	var files []*FileTree
+	var collectFiles func(t *FileTree) error
+	collectFiles = func(t *FileTree) error {
+		if !t.File.IsDir() {
+			files = append(files, t)
+			return nil
+		}
+
+		if err := os.MkdirAll(mirrorLocalPath(filesDir, t.Path), 0755); err != nil {
+			return err
+		}
+
+		for _, child := range t.Children {
+			if err := collectFiles(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := collectFiles(tree); err != nil {
+		return nil, err
+	}
+
+	result := c.mirrorFiles(projectFiles(projectID), filesDir, files, opts)
+	return result, result.err()
+}
+
+// fileSource is where mirrorFiles downloads files from.
+type fileSource struct {
+	// downloadPath returns the API path that downloads the file with the given ID.
+	downloadPath func(fileID int) string
+
+	// attrs identify the source in traces and metrics.
+	attrs []attribute.KeyValue
+}
+
+// projectFiles downloads files from a project.
+func projectFiles(projectID int) fileSource {
+	return fileSource{
+		downloadPath: func(fileID int) string {
+			return apiPath("/projects/%d/files/%d/download", projectID, fileID)
+		},
+		attrs: []attribute.KeyValue{projectIDAttr(projectID)},
+	}
+}
+
+// mirrorFiles downloads files from src into localDir, at their project paths, using
+// opts.Concurrency goroutines.
+func (c *Client) mirrorFiles(src fileSource, localDir string, files []*FileTree, opts MirrorOptions) *MirrorResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultMirrorConcurrency
+	}
+
+	work := make(chan *FileTree)
+	go func() {
+		defer close(work)
+		for _, f := range files {
+			work <- f
+		}
+	}()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = &MirrorResult{Failed: make(map[string]error)}
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range work {
+				n, skipped, err := c.mirrorFile(src, &f.File, mirrorLocalPath(localDir, f.Path), opts.VerifyChecksums)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					result.Failed[f.Path] = err
+				case skipped:
+					result.Skipped = append(result.Skipped, f.Path)
+				default:
+					result.Downloaded = append(result.Downloaded, f.Path)
+				}
+				result.Bytes += n
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return result
+}
+
+// err joins the failures in r into a single error, or returns nil if there were none.
+func (r *MirrorResult) err() error {
+	var errs []error
+	for p, err := range r.Failed {
+		errs = append(errs, fmt.Errorf("%s: %w", p, err))
+	}
+	return errors.Join(errs...)
+}
+
+// mirrorFile brings the local copy of file at localPath up to date. It returns the number of
+// bytes downloaded, and whether the file was skipped because it was already up to date.
+func (c *Client) mirrorFile(src fileSource, file *mcmodel.File, localPath string, verifyChecksum bool) (int64, bool, error) {
+	if upToDate, err := localFileMatches(localPath, file, verifyChecksum); err != nil || upToDate {
+		return 0, upToDate, err
+	}
+
+	op := c.startOperation("DownloadFile", src.attrs...)
+	n, err := op.downloadResumable(src.downloadPath(file.ID), file, localPath)
+	return n, false, op.end(err)
+}
+
+// downloadResumable downloads file from downloadPath to localPath. The file is first written
+// to a partial file next to localPath, which is renamed once the download is complete. If a
+// partial file is left from an earlier attempt at the same file version, only the rest of the
+// file is requested. A resumed download is checked against the file's checksum, when the
+// server has one, and downloaded again from the start if it doesn't match.
+func (op *operation) downloadResumable(downloadPath string, file *mcmodel.File, localPath string) (int64, error) {
+	// The partial is named for the file's ID so that a new version never resumes an old one.
+	partial := fmt.Sprintf("%s.%d%s", localPath, file.ID, partialSuffix)
+	n, resumed, err := op.downloadPartial(downloadPath, file, partial)
+	if err != nil {
+		return n, err
+	}
+
+	if resumed && file.Checksum != "" {
+		checksum, err := fileChecksum(partial)
+		if err != nil {
+			return n, err
+		}
+
+		if checksum != file.Checksum {
+			if err := os.Remove(partial); err != nil {
+				return n, err
+			}
+
+			restarted, _, err := op.downloadPartial(downloadPath, file, partial)
+			n += restarted
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, os.Rename(partial, localPath)
+}
+
+// downloadPartial downloads file into partial, resuming from the end of partial if it
+// already holds the start of the file. It returns the number of bytes downloaded and whether
+// the download was resumed.
+func (op *operation) downloadPartial(downloadPath string, file *mcmodel.File, partial string) (int64, bool, error) {
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, false, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if offset >= int64(file.Size) {
+		// The partial file can't be the start of this file, so start over.
+		offset = 0
+	}
+
+	resp, err := op.stream(apiRequest{
+		method: http.MethodGet,
+		path:   downloadPath,
+		prepare: func(r *resty.Request) {
+			if offset > 0 {
+				r.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+			}
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	body := resp.RawBody()
+	defer func() {
+		_ = body.Close()
+	}()
+
+	if resp.StatusCode() != http.StatusPartialContent {
+		// The server sent the whole file, so start over.
+		offset = 0
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return 0, false, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+
+	n, err := io.Copy(f, body)
+	op.addDownloadedBytes(n)
+	if err != nil {
+		return n, false, err
+	}
+
+	if err := f.Close(); err != nil {
+		return n, false, err
+	}
+
+	if size := offset + n; size != int64(file.Size) {
+		return n, false, fmt.Errorf("downloaded %d bytes, expected %d", size, file.Size)
+	}
+
+	return n, offset > 0, nil
+}
+
+// localFileMatches returns true if the file at localPath is the same as file. It compares
+// sizes, and checksums too when verifyChecksum is set and the server has one.
+func localFileMatches(localPath string, file *mcmodel.File, verifyChecksum bool) (bool, error) {
+	finfo, err := os.Stat(localPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	case finfo.Size() != int64(file.Size):
+		return false, nil
+	case !verifyChecksum || file.Checksum == "":
+		return true, nil
+	}
+
+	checksum, err := fileChecksum(localPath)
+	if err != nil {
+		return false, err
+	}
+
+	return checksum == file.Checksum, nil
+}
+
+// mirrorLocalPath returns where the file at projectPath is stored under localDir. The path is
+// cleaned so that a project path can't point outside of localDir.
+func mirrorLocalPath(localDir, projectPath string) string {
+	return filepath.Join(localDir, filepath.FromSlash(path.Clean("/"+projectPath)))
+}
+
+// exportProjectMetadata writes the project and its experiments, activities, entities, entity
+// states and datasets as JSON files in dir.
+func (c *Client) exportProjectMetadata(projectID int, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	project, err := c.GetProject(projectID)
+	if err != nil {
+		return err
+	}
+
+	experiments, err := collect(c.IterateExperiments(projectID, ListOptions{}))
+	if err != nil {
+		return err
+	}
+
+	activities, err := collect(c.IterateActivities(projectID, ListOptions{}))
+	if err != nil {
+		return err
+	}
+
+	entities, err := collect(c.IterateEntities(projectID, ListOptions{}))
+	if err != nil {
+		return err
+	}
+
+	states := []mcmodel.EntityState{}
+	for _, entity := range entities {
+		states = append(states, entity.EntityStates...)
+	}
+
+	datasets, err := collect(c.IterateDatasets(projectID, ListOptions{}))
+	if err != nil {
+		return err
+	}
+
+	exports := []struct {
+		name string
+		data any
+	}{
+		{"project.json", project},
+		{"experiments.json", nonNil(experiments)},
+		{"activities.json", nonNil(activities)},
+		{"entities.json", nonNil(entities)},
+		{"entity_states.json", states},
+		{"datasets.json", nonNil(datasets)},
+	}
+
+	for _, export := range exports {
+		if err := writeJSONFile(filepath.Join(dir, export.name), export.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nonNil returns items, or an empty slice if items is nil, so that it's written to JSON as
+// [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// writeJSONFile writes data as indented JSON to filePath.
+func writeJSONFile(filePath string, data any) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, b, 0644)
+}
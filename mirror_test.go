@@ -0,0 +1,187 @@
+package mcapi
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// handleMirrorProject serves a small project for MirrorProject. It returns a func reporting
+// the Range headers the download endpoint received, keyed by file ID.
+func handleMirrorProject(srv *fakeServer, contents map[int]string) func() map[int]string {
+	srv.handle(http.MethodGet, "/projects/1/files", fmt.Sprintf(`{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory", "directory_id": 1},
+		{"id": 2, "name": "raw", "path": "/raw", "mime_type": "directory", "directory_id": 1},
+		{"id": 3, "name": "empty", "path": "/empty", "mime_type": "directory", "directory_id": 1},
+		{"id": 10, "name": "a.txt", "directory_id": 1, "size": %d},
+		{"id": 11, "name": "b.txt", "directory_id": 2, "size": %d}]}`, len(contents[10]), len(contents[11])))
+	srv.handle(http.MethodGet, "/projects/1", `{"data": {"id": 1, "name": "thesis"}}`)
+	srv.handle(http.MethodGet, "/projects/1/experiments", `{"data": [{"id": 5, "name": "exp"}]}`)
+	srv.handle(http.MethodGet, "/projects/1/activities", `{"data": [{"id": 6, "name": "anneal"}]}`)
+	srv.handle(http.MethodGet, "/projects/1/entities", `{"data": [{"id": 7, "name": "s1", "entity_states": [{"id": 8, "entity_id": 7}]}]}`)
+	srv.handle(http.MethodGet, "/projects/1/datasets", `{"data": []}`)
+
+	var (
+		mu     sync.Mutex
+		ranges = map[int]string{}
+	)
+
+	for id, data := range contents {
+		id, data := id, data
+		srv.handleFunc(http.MethodGet, fmt.Sprintf("/projects/1/files/%d/download", id), func(w http.ResponseWriter, r *http.Request) {
+			rng := r.Header.Get("Range")
+			mu.Lock()
+			ranges[id] = rng
+			mu.Unlock()
+
+			if rng != "" {
+				var start int
+				_, _ = fmt.Sscanf(rng, "bytes=%d-", &start)
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte(data[start:]))
+				return
+			}
+			_, _ = w.Write([]byte(data))
+		})
+	}
+
+	return func() map[int]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return ranges
+	}
+}
+
+func TestClient_MirrorProject(t *testing.T) {
+	localDir := t.TempDir()
+	srv := newFakeServer(t)
+	ranges := handleMirrorProject(srv, map[int]string{10: "contents of a", 11: "contents of b"})
+
+	// Leave a partial download of b.txt behind, as if an earlier mirror was interrupted.
+	if err := os.MkdirAll(filepath.Join(localDir, "files", "raw"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(localDir, "files", "raw", "b.txt.11"+partialSuffix), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := srv.client().MirrorProject(1, localDir, MirrorOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(result.Downloaded)
+	if strings.Join(result.Downloaded, ",") != "/a.txt,/raw/b.txt" || len(result.Skipped) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	for p, expected := range map[string]string{"a.txt": "contents of a", "raw/b.txt": "contents of b"} {
+		got, err := os.ReadFile(filepath.Join(localDir, "files", filepath.FromSlash(p)))
+		if err != nil || string(got) != expected {
+			t.Errorf("%s: got %q, %v", p, got, err)
+		}
+	}
+
+	if r := ranges()[11]; r != "bytes=7-" {
+		t.Errorf("expected b.txt to be resumed, got Range %q", r)
+	}
+
+	if _, err := os.Stat(filepath.Join(localDir, "files", "empty")); err != nil {
+		t.Errorf("expected empty directory to be created: %v", err)
+	}
+
+	var states []map[string]any
+	b, err := os.ReadFile(filepath.Join(localDir, "metadata", "entity_states.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &states); err != nil || len(states) != 1 {
+		t.Errorf("unexpected entity states %s", b)
+	}
+
+	for _, name := range []string{"project.json", "experiments.json", "activities.json", "entities.json", "datasets.json"} {
+		if _, err := os.Stat(filepath.Join(localDir, "metadata", name)); err != nil {
+			t.Errorf("expected %s to be exported: %v", name, err)
+		}
+	}
+
+	// A second mirror has nothing to download.
+	result, err = srv.client().MirrorProject(1, localDir, MirrorOptions{SkipMetadata: true, VerifyChecksums: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Downloaded) != 0 || len(result.Skipped) != 2 || result.Bytes != 0 {
+		t.Errorf("expected everything to be skipped, got %+v", result)
+	}
+}
+
+func TestClient_MirrorProjectRestartsMismatchedPartial(t *testing.T) {
+	const contents = "NEWNEWNEWNEW"
+	localDir := t.TempDir()
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/files", fmt.Sprintf(`{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory", "directory_id": 1},
+		{"id": 10, "name": "a.txt", "directory_id": 1, "size": %d, "checksum": "%x"}]}`,
+		len(contents), md5.Sum([]byte(contents))))
+
+	var ranges []string
+	srv.handleFunc(http.MethodGet, "/projects/1/files/10/download", func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		ranges = append(ranges, rng)
+		if rng != "" {
+			var start int
+			_, _ = fmt.Sscanf(rng, "bytes=%d-", &start)
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(contents[start:]))
+			return
+		}
+		_, _ = w.Write([]byte(contents))
+	})
+
+	// A partial left from an older version of a.txt, which must not be resumed at all, and
+	// a partial for this version whose contents no longer match the file.
+	filesDir := filepath.Join(localDir, "files")
+	if err := os.MkdirAll(filesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"a.txt.9" + partialSuffix: "NEW", "a.txt.10" + partialSuffix: "OLDOLD"} {
+		if err := os.WriteFile(filepath.Join(filesDir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := srv.client().MirrorProject(1, localDir, MirrorOptions{SkipMetadata: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(filesDir, "a.txt")); err != nil || string(got) != contents {
+		t.Errorf("a.txt: got %q, %v", got, err)
+	}
+
+	if strings.Join(ranges, ",") != "bytes=6-," || len(result.Downloaded) != 1 {
+		t.Errorf("expected a resumed download followed by a full one, got ranges %q and result %+v", ranges, result)
+	}
+}
+
+func TestClient_MirrorProjectReportsFailures(t *testing.T) {
+	srv := newFakeServer(t)
+	handleMirrorProject(srv, map[int]string{10: "contents of a"})
+
+	result, err := srv.client().MirrorProject(1, t.TempDir(), MirrorOptions{SkipMetadata: true})
+	if err == nil {
+		t.Fatal("expected an error for the file that couldn't be downloaded")
+	}
+
+	if len(result.Downloaded) != 1 || result.Failed["/raw/b.txt"] == nil {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,269 @@
+package mcapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListOptions controls paging, sorting and filtering for list calls. The zero value
+// requests the first page using the server's default page size.
+type ListOptions struct {
+	// Page is the page to return, starting from 1.
+	Page int
+
+	// PerPage is the number of items per page. The server picks a default if it is zero.
+	PerPage int
+
+	// Sort is the field to sort by. Prefix the field with "-" to sort descending, for
+	// example "-created_at".
+	Sort string
+
+	// Filter restricts the items returned. Each entry is sent as filter[key]=value.
+	Filter map[string]string
+}
+
+// queryParams returns the options as query parameters.
+func (opts ListOptions) queryParams() map[string]string {
+	params := make(map[string]string)
+	if opts.Page > 0 {
+		params["page"] = strconv.Itoa(opts.Page)
+	}
+
+	if opts.PerPage > 0 {
+		params["per_page"] = strconv.Itoa(opts.PerPage)
+	}
+
+	if opts.Sort != "" {
+		params["sort"] = opts.Sort
+	}
+
+	for key, value := range opts.Filter {
+		params[fmt.Sprintf("filter[%s]", key)] = value
+	}
+
+	return params
+}
+
+// Pagination is the paging information returned with a page of results.
+type Pagination struct {
+	CurrentPage int    `json:"current_page"`
+	LastPage    int    `json:"last_page"`
+	PerPage     int    `json:"per_page"`
+	Total       int    `json:"total"`
+	NextURL     string `json:"-"`
+}
+
+// HasNext returns true if there are pages after this one.
+func (p Pagination) HasNext() bool {
+	return p.NextURL != "" || p.CurrentPage < p.LastPage
+}
+
+// Page is a single page of results from a list call.
+type Page[T any] struct {
+	Items []T
+	Pagination
+}
+
+// pageResponse is the json for a paginated response, eg
+// {"data": [...], "links": {"next": "..."}, "meta": {"current_page": 1,...}}.
+// Responses from endpoints that don't paginate only have the data key, and are
+// treated as a single page.
+type pageResponse[T any] struct {
+	Data  []T `json:"data"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+	Meta Pagination `json:"meta"`
+}
+
+// listPage retrieves a single page of T from path. It's a function rather than a method on
+// Client because Go doesn't allow type parameters on methods.
+func listPage[T any](c *Client, opName, path string, opts ListOptions, attrs ...attribute.KeyValue) (*Page[T], error) {
+	var result pageResponse[T]
+	err := c.send(apiRequest{
+		op:     opName,
+		method: http.MethodGet,
+		path:   path,
+		query:  opts.queryParams(),
+		attrs:  attrs,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: result.Data, Pagination: result.Meta}
+	page.NextURL = result.Links.Next
+	if page.CurrentPage == 0 {
+		// The endpoint didn't paginate, so everything is on this one page.
+		page.CurrentPage = 1
+		page.LastPage = 1
+		page.Total = len(page.Items)
+	}
+
+	return page, nil
+}
+
+// Iterator walks the items returned by a list call, lazily fetching each page as it is
+// needed. Use it like a bufio.Scanner:
+//
+//	it := c.IterateProjects(ListOptions{})
+//	for it.Next() {
+//		project := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	fetch func(opts ListOptions) (*Page[T], error)
+	opts  ListOptions
+	page  *Page[T]
+	i     int
+	err   error
+}
+
+// newIterator creates an iterator that starts at opts.Page.
+func newIterator[T any](opts ListOptions, fetch func(opts ListOptions) (*Page[T], error)) *Iterator[T] {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	return &Iterator[T]{fetch: fetch, opts: opts}
+}
+
+// Next advances to the next item, fetching the next page if needed. It returns false
+// when there are no more items or an error occurred.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.page != nil && it.i+1 < len(it.page.Items) {
+		it.i++
+		return true
+	}
+
+	for {
+		if it.page != nil {
+			if !it.page.HasNext() {
+				return false
+			}
+			it.opts.Page = it.page.CurrentPage + 1
+		}
+
+		it.page, it.err = it.fetch(it.opts)
+		if it.err != nil {
+			return false
+		}
+
+		it.i = 0
+		if len(it.page.Items) > 0 {
+			return true
+		}
+	}
+}
+
+// Value returns the current item. It should only be called after Next returns true.
+func (it *Iterator[T]) Value() T {
+	return it.page.Items[it.i]
+}
+
+// Err returns the error, if any, that stopped iteration.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Pagination returns the paging information for the most recently fetched page.
+func (it *Iterator[T]) Pagination() Pagination {
+	if it.page == nil {
+		return Pagination{}
+	}
+	return it.page.Pagination
+}
+
+// collect walks every item in it and returns them.
+func collect[T any](it *Iterator[T]) ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+// ListProjectsPage returns one page of the projects the user is a member of.
+func (c *Client) ListProjectsPage(opts ListOptions) (*Page[mcmodel.Project], error) {
+	return listPage[mcmodel.Project](c, "ListProjects", "/projects", opts)
+}
+
+// IterateProjects returns an iterator over the projects the user is a member of.
+func (c *Client) IterateProjects(opts ListOptions) *Iterator[mcmodel.Project] {
+	return newIterator(opts, c.ListProjectsPage)
+}
+
+// ListDatasetsPage returns one page of the datasets in a project.
+func (c *Client) ListDatasetsPage(projectID int, opts ListOptions) (*Page[mcmodel.Dataset], error) {
+	path := apiPath("/projects/%d/datasets", projectID)
+	return listPage[mcmodel.Dataset](c, "ListDatasets", path, opts, projectIDAttr(projectID))
+}
+
+// IterateDatasets returns an iterator over the datasets in a project.
+func (c *Client) IterateDatasets(projectID int, opts ListOptions) *Iterator[mcmodel.Dataset] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Dataset], error) {
+		return c.ListDatasetsPage(projectID, opts)
+	})
+}
+
+// ListFilesPage returns one page of the files and directories in a project.
+func (c *Client) ListFilesPage(projectID int, opts ListOptions) (*Page[mcmodel.File], error) {
+	path := apiPath("/projects/%d/files", projectID)
+	return listPage[mcmodel.File](c, "ListFiles", path, opts, projectIDAttr(projectID))
+}
+
+// IterateFiles returns an iterator over the files and directories in a project.
+func (c *Client) IterateFiles(projectID int, opts ListOptions) *Iterator[mcmodel.File] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.File], error) {
+		return c.ListFilesPage(projectID, opts)
+	})
+}
+
+// ListEntitiesPage returns one page of the entities (samples) in a project.
+func (c *Client) ListEntitiesPage(projectID int, opts ListOptions) (*Page[mcmodel.Entity], error) {
+	path := apiPath("/projects/%d/entities", projectID)
+	return listPage[mcmodel.Entity](c, "ListEntities", path, opts, projectIDAttr(projectID))
+}
+
+// IterateEntities returns an iterator over the entities (samples) in a project.
+func (c *Client) IterateEntities(projectID int, opts ListOptions) *Iterator[mcmodel.Entity] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Entity], error) {
+		return c.ListEntitiesPage(projectID, opts)
+	})
+}
+
+// ListActivitiesPage returns one page of the activities (processes) in a project.
+func (c *Client) ListActivitiesPage(projectID int, opts ListOptions) (*Page[mcmodel.Activity], error) {
+	path := apiPath("/projects/%d/activities", projectID)
+	return listPage[mcmodel.Activity](c, "ListActivities", path, opts, projectIDAttr(projectID))
+}
+
+// IterateActivities returns an iterator over the activities (processes) in a project.
+func (c *Client) IterateActivities(projectID int, opts ListOptions) *Iterator[mcmodel.Activity] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Activity], error) {
+		return c.ListActivitiesPage(projectID, opts)
+	})
+}
+
+// ListExperimentsPage returns one page of the experiments in a project.
+func (c *Client) ListExperimentsPage(projectID int, opts ListOptions) (*Page[mcmodel.Experiment], error) {
+	path := apiPath("/projects/%d/experiments", projectID)
+	return listPage[mcmodel.Experiment](c, "ListExperiments", path, opts, projectIDAttr(projectID))
+}
+
+// IterateExperiments returns an iterator over the experiments in a project.
+func (c *Client) IterateExperiments(projectID int, opts ListOptions) *Iterator[mcmodel.Experiment] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Experiment], error) {
+		return c.ListExperimentsPage(projectID, opts)
+	})
+}
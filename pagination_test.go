@@ -0,0 +1,76 @@
+package mcapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListProjectsWalksAllPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("sort") && (r.URL.Query().Get("sort") != "name" || r.URL.Query().Get("filter[owner_id]") != "3") {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		page := r.URL.Query().Get("page")
+		switch page {
+		case "1":
+			writeJSON(w, `{"data": [{"id": 1}, {"id": 2}], "links": {"next": "/projects?page=2"}, "meta": {"current_page": 1, "last_page": 2, "per_page": 2, "total": 3}}`)
+		case "2":
+			writeJSON(w, `{"data": [{"id": 3}], "links": {"next": null}, "meta": {"current_page": 2, "last_page": 2, "per_page": 2, "total": 3}}`)
+		default:
+			t.Errorf("unexpected page %q", page)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL})
+	projects, err := c.ListProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(projects) != 3 {
+		t.Fatalf("expected 3 projects, got %d", len(projects))
+	}
+
+	for i, p := range projects {
+		if p.ID != i+1 {
+			t.Errorf("expected project %d at index %d, got %d", i+1, i, p.ID)
+		}
+	}
+
+	it := c.IterateProjects(ListOptions{Sort: "name", Filter: map[string]string{"owner_id": "3"}})
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 || it.Pagination().Total != 3 {
+		t.Errorf("expected 3 projects with total 3, got %d with total %d", count, it.Pagination().Total)
+	}
+}
+
+func TestClient_ListDatasetsUnpaginated(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		writeJSON(w, `{"data": [{"id": 1}, {"id": 2}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL})
+	datasets, err := c.ListDatasets(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(datasets) != 2 || requests != 1 {
+		t.Errorf("expected 2 datasets in 1 request, got %d in %d requests", len(datasets), requests)
+	}
+}
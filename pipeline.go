@@ -0,0 +1,218 @@
+package mcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// apiRequest describes a single call to the API. Client methods fill one in and pass it
+// to do or send, which take care of building the request, hooks, telemetry, retries and
+// error handling the same way for every call.
+type apiRequest struct {
+	// op is the name of the operation, which is the name of the client method.
+	op string
+
+	// method is the HTTP method and path the path under BaseURL. Build paths with apiPath
+	// so that any string arguments are escaped.
+	method string
+	path   string
+
+	// body, when not nil, is sent as the json body of the request.
+	body any
+
+	// query holds query parameters to add to the URL.
+	query map[string]string
+
+	// attrs are added to the operation's span.
+	attrs []attribute.KeyValue
+
+	// upload marks the request as a file upload so it counts against the upload rate limit.
+	upload bool
+
+	// readOnly marks a request that only looks data up, even though it uses a method such
+	// as POST, so that hooks such as ReadOnly don't treat it as a mutation.
+	readOnly bool
+
+	// prepare, when set, is called to make additional changes to the request, such as
+	// attaching files.
+	prepare func(r *resty.Request)
+}
+
+// apiPath builds a URL path from format and args like fmt.Sprintf. String arguments are path
+// escaped so that names containing characters such as "/" or "?" can't change the path.
+func apiPath(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			escaped[i] = url.PathEscape(s)
+		} else {
+			escaped[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// do sends req and decodes the data key of the response into a new T. It's a function
+// rather than a method because Go doesn't allow type parameters on methods.
+func do[T any](c *Client, req apiRequest) (*T, error) {
+	result := new(T)
+	if err := c.send(req, &DataWrapper{result}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// send runs req as its own operation, decoding the response into result when result isn't nil.
+func (c *Client) send(req apiRequest, result any) error {
+	op := c.startOperation(req.op, req.attrs...)
+	return op.end(op.send(req, result))
+}
+
+// download runs req as its own operation, copying the body of the response to w. It returns
+// the number of bytes written.
+func (c *Client) download(req apiRequest, w io.Writer) (int64, error) {
+	op := c.startOperation(req.op, req.attrs...)
+	n, err := op.download(req, w)
+	return n, op.end(err)
+}
+
+// send makes req as part of op. It's used directly by calls that need to do more work in
+// the operation than a single request, such as recording the number of bytes uploaded.
+func (op *operation) send(req apiRequest, result any) error {
+	resp, err := op.execute(req, result)
+	return checkError(resp, err)
+}
+
+// execute builds and makes the request for req, returning the raw response.
+func (op *operation) execute(req apiRequest, result any) (*resty.Response, error) {
+	ctx := op.ctx
+	if req.upload {
+		ctx = asUpload(ctx)
+	}
+
+	if req.readOnly {
+		ctx = context.WithValue(ctx, readOnlyKey{}, true)
+	}
+
+	r := op.c.r().
+		SetContext(ctx).
+		SetError(&ErrorResponse{})
+
+	if req.body != nil {
+		r.SetBody(req.body)
+	}
+
+	if len(req.query) != 0 {
+		r.SetQueryParams(req.query)
+	}
+
+	if result != nil {
+		r.SetResult(result)
+	}
+
+	if req.prepare != nil {
+		req.prepare(r)
+	}
+
+	return r.Execute(req.method, op.c.BaseURL+req.path)
+}
+
+// download makes req as part of op and copies the body of the response to w, returning the
+// number of bytes written. The body is streamed rather than held in memory.
+func (op *operation) download(req apiRequest, w io.Writer) (int64, error) {
+	resp, err := op.stream(req)
+	if err != nil {
+		return 0, err
+	}
+
+	body := resp.RawBody()
+	defer func() {
+		_ = body.Close()
+	}()
+
+	n, err := io.Copy(w, body)
+	op.addDownloadedBytes(n)
+	return n, err
+}
+
+// stream makes req as part of op without reading the response. On success the caller must
+// close the response's RawBody. Error responses are read and returned as an APIError.
+func (op *operation) stream(req apiRequest) (*resty.Response, error) {
+	prepare := req.prepare
+	req.prepare = func(r *resty.Request) {
+		r.SetDoNotParseResponse(true)
+		if prepare != nil {
+			prepare(r)
+		}
+	}
+
+	resp, err := op.execute(req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsError() {
+		body := resp.RawBody()
+		defer func() {
+			_ = body.Close()
+		}()
+
+		msg, _ := io.ReadAll(io.LimitReader(body, maxLoggedBodySize))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode(),
+			Status:     fmt.Sprintf("%s: %s", resp.Status(), msg),
+		}
+	}
+
+	return resp, nil
+}
+
+// closeRetriedBody is a resty retry hook that closes the body of a response that is about to
+// be retried. resty doesn't close the body of streamed responses, which are made with
+// SetDoNotParseResponse, so without this the connection and any in flight slot held by the
+// response would never be released. Closing a body resty already read is harmless.
+func closeRetriedBody(resp *resty.Response, _ error) {
+	if resp != nil && resp.RawResponse != nil && resp.RawResponse.Body != nil {
+		_ = resp.RawResponse.Body.Close()
+	}
+}
+
+// shouldRetry is the resty retry condition used when ClientArgs.MaxRetries is set. Requests
+// that never reached the server, such as ones vetoed by a hook, are not retried. Connection
+// errors and gateway errors are only retried for idempotent methods, so that a POST that
+// might have been processed isn't repeated. A 429 (too many requests) is always retried.
+func shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+
+	idempotent := isIdempotent(resp.Request.Method)
+	if err != nil {
+		return idempotent
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// isIdempotent returns true if repeating a request with method has no additional effect.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
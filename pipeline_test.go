@@ -0,0 +1,196 @@
+package mcapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient_Endpoints(t *testing.T) {
+	uploadPath := filepath.Join(t.TempDir(), "upload.txt")
+	if err := os.WriteFile(uploadPath, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    string
+		response string
+		call     func(c *Client) error
+	}{
+		{"CreateProject", http.MethodPost, "/projects", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateProject(CreateProjectRequest{Name: "p"})
+			return err
+		}},
+		{"GetProject", http.MethodGet, "/projects/1", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.GetProject(1)
+			return err
+		}},
+		{"DeleteProject", http.MethodDelete, "/projects/1", "", `{}`, func(c *Client) error {
+			return c.DeleteProject(1)
+		}},
+		{"CreateExperiment", http.MethodPost, "/experiments", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateExperiment(CreateExperimentRequest{ProjectID: 1})
+			return err
+		}},
+		{"CreateDataset", http.MethodPost, "/projects/1/datasets", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateDataset(1, CreateOrUpdateDatasetRequest{})
+			return err
+		}},
+		{"GetDataset", http.MethodGet, "/projects/1/datasets/2", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.GetDataset(1, 2)
+			return err
+		}},
+		{"UpdateDataset", http.MethodPut, "/projects/1/datasets/2", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.UpdateDataset(1, 2, CreateOrUpdateDatasetRequest{})
+			return err
+		}},
+		{"UpdateDatasetFileSelection", http.MethodPut, "/projects/1/datasets/2/change_file_selection", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.UpdateDatasetFileSelection(1, 2, DatasetFileSelection{})
+			return err
+		}},
+		{"PublishDataset", http.MethodPut, "/datasets/2/publish", "test=true", `{"data": {}}`, func(c *Client) error {
+			_, err := c.PublishDataset(1, 2, true)
+			return err
+		}},
+		{"UnpublishDataset", http.MethodPut, "/datasets/2/unpublish", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.UnpublishDataset(1, 2)
+			return err
+		}},
+		{"CreateActivity", http.MethodPost, "/activities", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateActivity(CreateActivityRequest{})
+			return err
+		}},
+		{"CreateEntity", http.MethodPost, "/entities", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateEntity(CreateEntityRequest{})
+			return err
+		}},
+		{"CreateEntityState", http.MethodPost, "/projects/1/entities/2/activities/3/create-entity-state", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateEntityState(1, 2, 3, CreateEntityStateRequest{})
+			return err
+		}},
+		{"GetFileByPath", http.MethodPost, "/files/by_path", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.GetFileByPath(1, "/a/b.txt")
+			return err
+		}},
+		{"CreateDirectoryByPath", http.MethodPost, "/directories/by-path", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.CreateDirectoryByPath(1, "/a")
+			return err
+		}},
+		{"UploadFile", http.MethodPost, "/projects/1/files/2/upload", "", `{"data": [{}]}`, func(c *Client) error {
+			_, err := c.UploadFile(1, 2, uploadPath)
+			return err
+		}},
+		{"ListProjects", http.MethodGet, "/projects", "page=1", `{"data": []}`, func(c *Client) error {
+			_, err := c.ListProjects()
+			return err
+		}},
+		{"ListDatasets", http.MethodGet, "/projects/1/datasets", "page=1", `{"data": []}`, func(c *Client) error {
+			_, err := c.ListDatasets(1)
+			return err
+		}},
+		{"MintDOIForDataset", http.MethodPut, "/projects/1/datasets/2/assign_doi", "", `{"data": {}}`, func(c *Client) error {
+			_, err := c.MintDOIForDataset(1, 2, false)
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newFakeServer(t)
+			srv.handle(test.method, test.path, test.response)
+
+			if err := test.call(srv.client()); err != nil {
+				t.Fatalf("%s failed: %s", test.name, err)
+			}
+
+			received := srv.received()
+			if len(received) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(received))
+			}
+
+			if received[0].RawQuery != test.query {
+				t.Errorf("expected query %q, got %q", test.query, received[0].RawQuery)
+			}
+		})
+	}
+}
+
+func TestAPIPath_EscapesStrings(t *testing.T) {
+	path := apiPath("/projects/%d/files/%s", 1, "a/b?c")
+	if path != "/projects/1/files/a%2Fb%3Fc" {
+		t.Errorf("unexpected path %q", path)
+	}
+}
+
+func TestClient_RetriesUnavailable(t *testing.T) {
+	srv := newFakeServer(t)
+	attempts := 0
+	srv.handleFunc(http.MethodGet, "/projects/1", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, `{"data": {"id": 1}}`)
+	})
+	srv.handleFunc(http.MethodPost, "/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, MaxRetries: 3})
+	c.rClient.SetRetryWaitTime(0)
+
+	proj, err := c.GetProject(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if proj.ID != 1 || attempts != 3 {
+		t.Errorf("expected project 1 after 3 attempts, got project %d after %d attempts", proj.ID, attempts)
+	}
+
+	// POST is not idempotent so it shouldn't be retried on a 503.
+	before := len(srv.received())
+	if _, err := c.CreateProject(CreateProjectRequest{}); err == nil {
+		t.Fatal("expected CreateProject to fail")
+	}
+
+	if n := len(srv.received()) - before; n != 1 {
+		t.Errorf("expected CreateProject to be sent once, sent %d times", n)
+	}
+}
+
+func TestClient_RetriedDownloadReleasesInFlightSlot(t *testing.T) {
+	srv := newFakeServer(t)
+	attempts := 0
+	srv.handleFunc(http.MethodGet, "/projects/1/files/10/download", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("data"))
+	})
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, MaxRetries: 2, RateLimit: RateLimit{MaxInFlight: 1}})
+	c.rClient.SetRetryWaitTime(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if _, err := c.WithContext(ctx).DownloadFile(1, IDRef(10), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "data" || attempts != 2 {
+		t.Errorf("expected the download to succeed on attempt 2, got %q after %d attempts", buf.String(), attempts)
+	}
+}
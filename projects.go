@@ -0,0 +1,105 @@
+package mcapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProjectRole is the role a user has in a project.
+type ProjectRole string
+
+const (
+	// ProjectRoleMember can read and change the project's data.
+	ProjectRoleMember ProjectRole = "member"
+
+	// ProjectRoleAdmin can also manage the project's members.
+	ProjectRoleAdmin ProjectRole = "admin"
+)
+
+// ProjectMember is a user who has access to a project.
+type ProjectMember struct {
+	mcmodel.User
+	Role ProjectRole `json:"role"`
+}
+
+// UpdateProject changes the name, description or summary of a project. Fields left blank in
+// the request are not changed. It returns the updated project.
+func (c *Client) UpdateProject(projectID int, req UpdateProjectRequest) (*mcmodel.Project, error) {
+	return do[mcmodel.Project](c, apiRequest{
+		op:     "UpdateProject",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d", projectID),
+		body:   req,
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+}
+
+// ListProjectMembers lists the users who have access to a project, along with their role.
+func (c *Client) ListProjectMembers(projectID int) ([]ProjectMember, error) {
+	members, err := do[[]ProjectMember](c, apiRequest{
+		op:     "ListProjectMembers",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/members", projectID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *members, nil
+}
+
+// AddProjectMember gives the user access to a project as a member.
+func (c *Client) AddProjectMember(projectID, userID int) error {
+	return c.changeProjectMember("AddProjectMember", "/projects/%d/add-user/%d", projectID, userID)
+}
+
+// AddProjectMemberByEmail gives the user with the given email access to a project as a member.
+func (c *Client) AddProjectMemberByEmail(projectID int, email string) error {
+	user, err := c.GetUserByEmail(email)
+	if err != nil {
+		return fmt.Errorf("unable to find user %s: %w", email, err)
+	}
+	return c.AddProjectMember(projectID, user.ID)
+}
+
+// RemoveProjectMember removes the user's access to a project.
+func (c *Client) RemoveProjectMember(projectID, userID int) error {
+	return c.changeProjectMember("RemoveProjectMember", "/projects/%d/remove-user/%d", projectID, userID)
+}
+
+// RemoveProjectMemberByEmail removes access to a project for the user with the given email.
+func (c *Client) RemoveProjectMemberByEmail(projectID int, email string) error {
+	user, err := c.GetUserByEmail(email)
+	if err != nil {
+		return fmt.Errorf("unable to find user %s: %w", email, err)
+	}
+	return c.RemoveProjectMember(projectID, user.ID)
+}
+
+// SetProjectMemberRole changes the role of a user who is already a member of a project.
+// Making a user a ProjectRoleAdmin lets them manage the project's members, and setting
+// them back to ProjectRoleMember removes that.
+func (c *Client) SetProjectMemberRole(projectID, userID int, role ProjectRole) error {
+	switch role {
+	case ProjectRoleAdmin:
+		return c.changeProjectMember("SetProjectMemberRole", "/projects/%d/add-admin/%d", projectID, userID)
+	case ProjectRoleMember:
+		return c.changeProjectMember("SetProjectMemberRole", "/projects/%d/remove-admin/%d", projectID, userID)
+	default:
+		return fmt.Errorf("role must be either '%s' or '%s'", ProjectRoleMember, ProjectRoleAdmin)
+	}
+}
+
+// changeProjectMember makes one of the membership calls, which all take the project and
+// user IDs in the path.
+func (c *Client) changeProjectMember(opName, pathFormat string, projectID, userID int) error {
+	return c.send(apiRequest{
+		op:     opName,
+		method: http.MethodPut,
+		path:   apiPath(pathFormat, projectID, userID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), attribute.Int("mcapi.user_id", userID)},
+	}, nil)
+}
@@ -0,0 +1,70 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_UpdateProject(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodPut, "/projects/1", `{"data": {"id": 1, "name": "renamed"}}`)
+
+	proj, err := srv.client().UpdateProject(1, UpdateProjectRequest{Name: "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if proj.Name != "renamed" {
+		t.Errorf("expected renamed project, got %q", proj.Name)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(srv.received()[0].Body, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := body["description"]; ok {
+		t.Errorf("blank fields should not be sent: %v", body)
+	}
+}
+
+func TestClient_ProjectMembers(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/members", `{"data": [{"id": 5, "email": "a@b.edu", "role": "admin"}]}`)
+	srv.handle(http.MethodGet, "/users/by-email/new@lab.edu", `{"data": {"id": 7, "email": "new@lab.edu"}}`)
+	srv.handle(http.MethodPut, "/projects/1/add-user/7", `{}`)
+	srv.handle(http.MethodPut, "/projects/1/remove-user/7", `{}`)
+	srv.handle(http.MethodPut, "/projects/1/add-admin/7", `{}`)
+	srv.handle(http.MethodPut, "/projects/1/remove-admin/7", `{}`)
+	c := srv.client()
+
+	members, err := c.ListProjectMembers(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(members) != 1 || members[0].ID != 5 || members[0].Role != ProjectRoleAdmin {
+		t.Errorf("unexpected members %+v", members)
+	}
+
+	if err := c.AddProjectMemberByEmail(1, "new@lab.edu"); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.SetProjectMemberRole(1, 7, ProjectRoleAdmin); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.SetProjectMemberRole(1, 7, ProjectRoleMember); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.RemoveProjectMemberByEmail(1, "new@lab.edu"); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.SetProjectMemberRole(1, 7, "owner"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
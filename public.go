@@ -0,0 +1,209 @@
+package mcapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NewPublicClient creates a client that doesn't have an API key. It can only make the
+// calls for published data, such as SearchPublishedDatasets and DownloadPublishedDataset,
+// which don't need one. The APIKey in args, if any, is ignored.
+func NewPublicClient(args *ClientArgs) *Client {
+	publicArgs := ClientArgs{}
+	if args != nil {
+		publicArgs = *args
+	}
+	publicArgs.APIKey = ""
+	return NewClient(&publicArgs)
+}
+
+// PublishedDatasetQuery selects published datasets. Its fields are combined, so a dataset
+// must match all the fields that are set. The zero value matches every published dataset.
+type PublishedDatasetQuery struct {
+	// Keyword matches datasets whose name, summary or description contains it.
+	Keyword string
+
+	// Tag matches datasets with this tag.
+	Tag string
+
+	// Author matches datasets with an author whose name contains it.
+	Author string
+
+	// CommunityID matches datasets accepted into the community.
+	CommunityID int
+
+	// PublishedAfter and PublishedBefore match datasets published on or after, and on or
+	// before, the given days.
+	PublishedAfter  time.Time
+	PublishedBefore time.Time
+}
+
+// listOptions returns opts with the query added to its filters.
+func (q PublishedDatasetQuery) listOptions(opts ListOptions) ListOptions {
+	filter := make(map[string]string, len(opts.Filter))
+	for key, value := range opts.Filter {
+		filter[key] = value
+	}
+
+	setFilter := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			filter[key] = value
+		}
+	}
+
+	setFilter("search", q.Keyword)
+	setFilter("tag", q.Tag)
+	setFilter("author", q.Author)
+	if q.CommunityID != 0 {
+		setFilter("community_id", strconv.Itoa(q.CommunityID))
+	}
+
+	if !q.PublishedAfter.IsZero() {
+		setFilter("published_after", q.PublishedAfter.Format(time.DateOnly))
+	}
+
+	if !q.PublishedBefore.IsZero() {
+		setFilter("published_before", q.PublishedBefore.Format(time.DateOnly))
+	}
+
+	opts.Filter = filter
+	return opts
+}
+
+// SearchPublishedDatasetsPage returns one page of the published datasets matching query.
+func (c *Client) SearchPublishedDatasetsPage(query PublishedDatasetQuery, opts ListOptions) (*Page[mcmodel.Dataset], error) {
+	return listPage[mcmodel.Dataset](c, "SearchPublishedDatasets", "/public/datasets", query.listOptions(opts))
+}
+
+// IteratePublishedDatasets returns an iterator over the published datasets matching query.
+func (c *Client) IteratePublishedDatasets(query PublishedDatasetQuery, opts ListOptions) *Iterator[mcmodel.Dataset] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.Dataset], error) {
+		return c.SearchPublishedDatasetsPage(query, opts)
+	})
+}
+
+// SearchPublishedDatasets lists all the published datasets matching query. Use
+// IteratePublishedDatasets for searches that match many datasets.
+func (c *Client) SearchPublishedDatasets(query PublishedDatasetQuery) ([]mcmodel.Dataset, error) {
+	return collect(c.IteratePublishedDatasets(query, ListOptions{}))
+}
+
+// GetPublishedDataset retrieves a published dataset along with its authors, tags, papers and
+// other metadata.
+func (c *Client) GetPublishedDataset(datasetID int) (*DatasetDetails, error) {
+	return do[DatasetDetails](c, apiRequest{
+		op:     "GetPublishedDataset",
+		method: http.MethodGet,
+		path:   apiPath("/public/datasets/%d", datasetID),
+		attrs:  []attribute.KeyValue{datasetIDAttr(datasetID)},
+	})
+}
+
+// ListPublishedDatasetFilesPage returns one page of the files in a published dataset.
+func (c *Client) ListPublishedDatasetFilesPage(datasetID int, opts ListOptions) (*Page[mcmodel.File], error) {
+	path := apiPath("/public/datasets/%d/files", datasetID)
+	return listPage[mcmodel.File](c, "ListPublishedDatasetFiles", path, opts, datasetIDAttr(datasetID))
+}
+
+// IteratePublishedDatasetFiles returns an iterator over the files in a published dataset.
+func (c *Client) IteratePublishedDatasetFiles(datasetID int, opts ListOptions) *Iterator[mcmodel.File] {
+	return newIterator(opts, func(opts ListOptions) (*Page[mcmodel.File], error) {
+		return c.ListPublishedDatasetFilesPage(datasetID, opts)
+	})
+}
+
+// ListPublishedDatasetFiles lists the files in a published dataset. The Path of each file is
+// its path in the dataset, and the files are sorted by path. Directories aren't included.
+func (c *Client) ListPublishedDatasetFiles(datasetID int) ([]mcmodel.File, error) {
+	op := c.startOperation("ListPublishedDatasetFiles", datasetIDAttr(datasetID))
+	files, err := op.client().listPublishedDatasetFiles(datasetID)
+	return files, op.end(err)
+}
+
+// listPublishedDatasetFiles does the work for ListPublishedDatasetFiles.
+func (c *Client) listPublishedDatasetFiles(datasetID int) ([]mcmodel.File, error) {
+	all, err := collect(c.IteratePublishedDatasetFiles(datasetID, ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]mcmodel.File, 0, len(all))
+	for _, f := range all {
+		if !f.IsDir() {
+			files = append(files, f)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// DownloadPublishedDataset downloads a published dataset into localDir. It downloads the
+// dataset's zip file, or, when opts.Tree is set, the dataset's files into localDir at their
+// paths in the dataset. As with DownloadDataset, a tree download can be run again to finish
+// an interrupted download.
+func (c *Client) DownloadPublishedDataset(datasetID int, localDir string, opts DatasetDownloadOptions) (*DatasetDownload, error) {
+	op := c.startOperation("DownloadPublishedDataset", datasetIDAttr(datasetID))
+	download, err := op.client().downloadPublishedDataset(datasetID, localDir, opts)
+	return download, op.end(err)
+}
+
+// downloadPublishedDataset does the work for DownloadPublishedDataset.
+func (c *Client) downloadPublishedDataset(datasetID int, localDir string, opts DatasetDownloadOptions) (*DatasetDownload, error) {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return nil, err
+	}
+
+	if !opts.Tree {
+		ds, err := c.GetPublishedDataset(datasetID)
+		if err != nil {
+			return nil, err
+		}
+
+		zipPath := filepath.Join(localDir, filepath.Base(ds.ZipfilePath("")))
+		req := apiRequest{
+			op:     "DownloadPublishedDatasetZip",
+			method: http.MethodGet,
+			path:   apiPath("/public/datasets/%d/download_zipfile", datasetID),
+			attrs:  []attribute.KeyValue{datasetIDAttr(datasetID)},
+		}
+		if err := c.downloadDatasetZip(req, zipPath); err != nil {
+			return nil, err
+		}
+		return &DatasetDownload{ZipPath: zipPath}, nil
+	}
+
+	files, err := c.listPublishedDatasetFiles(datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make([]*FileTree, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(mirrorLocalPath(localDir, f.Path)), 0755); err != nil {
+			return nil, err
+		}
+		trees = append(trees, &FileTree{File: f, Path: f.Path})
+	}
+
+	result := c.mirrorFiles(publishedDatasetFiles(datasetID), localDir, trees, MirrorOptions{Concurrency: opts.Concurrency})
+	return &DatasetDownload{Files: result}, result.err()
+}
+
+// publishedDatasetFiles downloads files from a published dataset.
+func publishedDatasetFiles(datasetID int) fileSource {
+	return fileSource{
+		downloadPath: func(fileID int) string {
+			return apiPath("/public/datasets/%d/files/%d/download", datasetID, fileID)
+		},
+		attrs: []attribute.KeyValue{datasetIDAttr(datasetID)},
+	}
+}
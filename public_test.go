@@ -0,0 +1,125 @@
+package mcapi
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPublicClient_SearchPublishedDatasets(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handleFunc(http.MethodGet, "/public/datasets", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("public client sent Authorization %q", auth)
+		}
+		writeJSON(w, `{"data": [{"id": 5, "name": "Mg alloys"}]}`)
+	})
+	c := NewPublicClient(&ClientArgs{BaseURL: srv.URL, APIKey: "ignored"})
+
+	datasets, err := c.SearchPublishedDatasets(PublishedDatasetQuery{
+		Keyword:        " magnesium ",
+		Tag:            "alloy",
+		Author:         "Smith",
+		CommunityID:    3,
+		PublishedAfter: time.Date(2023, 1, 2, 15, 0, 0, 0, time.UTC),
+	})
+	if err != nil || len(datasets) != 1 || datasets[0].ID != 5 {
+		t.Fatalf("SearchPublishedDatasets returned %+v, %v", datasets, err)
+	}
+
+	query, _ := url.ParseQuery(srv.received()[0].RawQuery)
+	want := map[string]string{
+		"filter[search]":          "magnesium",
+		"filter[tag]":             "alloy",
+		"filter[author]":          "Smith",
+		"filter[community_id]":    "3",
+		"filter[published_after]": "2023-01-02",
+		"page":                    "1",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s: got %q, want %q", key, got, value)
+		}
+	}
+
+	if query.Has("filter[published_before]") {
+		t.Error("unset fields should not be sent")
+	}
+}
+
+func TestPublicClient_GetPublishedDataset(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/public/datasets/5", `{"data": {"id": 5, "name": "Mg alloys", "tags": [{"value": "alloy"}]}}`)
+	c := NewPublicClient(&ClientArgs{BaseURL: srv.URL})
+
+	ds, err := c.GetPublishedDataset(5)
+	if err != nil || ds.Name != "Mg alloys" || len(ds.Tags) != 1 {
+		t.Errorf("GetPublishedDataset returned %+v, %v", ds, err)
+	}
+}
+
+// publishedDatasetServer serves a published dataset with two files and a directory.
+func publishedDatasetServer(t *testing.T) *fakeServer {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/public/datasets/5", `{"data": {"id": 5, "name": "Mg alloys"}}`)
+	srv.handle(http.MethodGet, "/public/datasets/5/files", `{"data": [
+		{"id": 12, "name": "b.txt", "path": "/raw/b.txt", "mime_type": "text/plain", "size": 1},
+		{"id": 10, "name": "raw", "path": "/raw", "mime_type": "directory"},
+		{"id": 11, "name": "a.txt", "path": "/a.txt", "mime_type": "text/plain", "size": 1}
+	]}`)
+	srv.handleFunc(http.MethodGet, "/public/datasets/5/files/11/download", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("a"))
+	})
+	srv.handleFunc(http.MethodGet, "/public/datasets/5/files/12/download", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("b"))
+	})
+	srv.handleFunc(http.MethodGet, "/public/datasets/5/download_zipfile", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("PK zip"))
+	})
+	return srv
+}
+
+func TestPublicClient_ListPublishedDatasetFiles(t *testing.T) {
+	srv := publishedDatasetServer(t)
+
+	files, err := NewPublicClient(&ClientArgs{BaseURL: srv.URL}).ListPublishedDatasetFiles(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 || files[0].Path != "/a.txt" || files[1].Path != "/raw/b.txt" {
+		t.Errorf("unexpected files %+v", files)
+	}
+}
+
+func TestPublicClient_DownloadPublishedDataset(t *testing.T) {
+	srv := publishedDatasetServer(t)
+	c := NewPublicClient(&ClientArgs{BaseURL: srv.URL})
+
+	zipDir := t.TempDir()
+	download, err := c.DownloadPublishedDataset(5, zipDir, DatasetDownloadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(zipDir, "mg-alloys.zip")); err != nil || string(b) != "PK zip" || download.ZipPath == "" {
+		t.Errorf("zip: got %q, %v, %+v", b, err, download)
+	}
+
+	treeDir := t.TempDir()
+	download, err = c.DownloadPublishedDataset(5, treeDir, DatasetDownloadOptions{Tree: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(download.Files.Downloaded) != 2 {
+		t.Errorf("unexpected download %+v", download.Files)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(treeDir, "raw", "b.txt")); err != nil || string(b) != "b" {
+		t.Errorf("raw/b.txt: got %q, %v", b, err)
+	}
+}
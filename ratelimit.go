@@ -0,0 +1,157 @@
+package mcapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit configures client side limits on requests. Limits are shared by every
+// goroutine using the same Client (including copies made with WithContext). The zero
+// value means no limit.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which tokens are added to the bucket. Zero
+	// means requests are not rate limited.
+	RequestsPerSecond float64
+
+	// Burst is the size of the token bucket, which is the number of requests that can
+	// be made at once before being limited to RequestsPerSecond. It defaults to 1.
+	Burst int
+
+	// MaxInFlight is the maximum number of requests that can be outstanding at once.
+	// Zero means no limit.
+	MaxInFlight int
+}
+
+// isZero returns true if no limits are set.
+func (l RateLimit) isZero() bool {
+	return l.RequestsPerSecond <= 0 && l.MaxInFlight <= 0
+}
+
+// budget enforces a RateLimit.
+type budget struct {
+	limiter  *rate.Limiter
+	inFlight chan struct{}
+}
+
+// newBudget creates a budget from l. It returns nil if l has no limits.
+func newBudget(l RateLimit) *budget {
+	if l.isZero() {
+		return nil
+	}
+
+	b := &budget{}
+	if l.RequestsPerSecond > 0 {
+		b.limiter = rate.NewLimiter(rate.Limit(l.RequestsPerSecond), max(l.Burst, 1))
+	}
+
+	if l.MaxInFlight > 0 {
+		b.inFlight = make(chan struct{}, l.MaxInFlight)
+	}
+
+	return b
+}
+
+// acquire waits until a request is allowed. On success the returned func must be called
+// to release the request's in flight slot.
+func (b *budget) acquire(ctx context.Context) (release func(), err error) {
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case b.inFlight <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-b.inFlight }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// uploadKey is the context key marking a request as a file upload.
+type uploadKey struct{}
+
+// asUpload marks requests made with ctx as uploads so that they are counted against
+// the upload budget rather than the metadata budget.
+func asUpload(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uploadKey{}, true)
+}
+
+// isUpload returns true if ctx was marked with asUpload.
+func isUpload(ctx context.Context) bool {
+	upload, _ := ctx.Value(uploadKey{}).(bool)
+	return upload
+}
+
+// limitedTransport is an http.RoundTripper that applies the client's rate limits before
+// passing requests on to the underlying transport. A request holds its in flight slot
+// until its response body is closed.
+type limitedTransport struct {
+	next     http.RoundTripper
+	metadata *budget
+	uploads  *budget
+}
+
+// newLimitedTransport wraps next with the given limits. It returns next unchanged if there
+// are no limits.
+func newLimitedTransport(next http.RoundTripper, metadata, uploads RateLimit) http.RoundTripper {
+	t := &limitedTransport{
+		next:     next,
+		metadata: newBudget(metadata),
+		uploads:  newBudget(uploads),
+	}
+
+	if t.metadata == nil && t.uploads == nil {
+		return next
+	}
+
+	return t
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.metadata
+	if isUpload(req.Context()) {
+		b = t.uploads
+	}
+
+	if b == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	release, err := b.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose releases an in flight slot when the response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements the io.Closer interface.
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,91 @@
+package mcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_MaxInFlight(t *testing.T) {
+	var inFlight, maxSeen atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxSeen.Load()
+			if n <= m || maxSeen.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		writeJSON(w, `{"data": {"id": 1}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, RateLimit: RateLimit{MaxInFlight: 2}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.CreateEntity(CreateEntityRequest{Name: "e"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if m := maxSeen.Load(); m > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", m)
+	}
+}
+
+func TestClient_RateLimitHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 1}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientArgs{BaseURL: srv.URL, RateLimit: RateLimit{RequestsPerSecond: 0.1, Burst: 1}})
+
+	// The first request uses the only token in the bucket.
+	if _, err := c.GetProject(1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.WithContext(ctx).GetProject(1); err == nil {
+		t.Fatal("expected rate limited request to fail when its context expires")
+	}
+}
+
+func TestBudget_SeparateUploadBudget(t *testing.T) {
+	transport := newLimitedTransport(http.DefaultTransport, RateLimit{MaxInFlight: 1}, RateLimit{})
+	lt, ok := transport.(*limitedTransport)
+	if !ok {
+		t.Fatal("expected a limitedTransport")
+	}
+
+	if lt.uploads != nil {
+		t.Errorf("uploads should not be limited")
+	}
+
+	release, err := lt.metadata.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := lt.metadata.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected second metadata request to wait, got %v", err)
+	}
+}
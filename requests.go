@@ -44,15 +44,17 @@ type DatasetFileSelection struct {
 }
 
 type CreateOrUpdateDatasetRequest struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Summary     string   `json:"summary"`
-	License     string   `json:"license"`
-	Funding     string   `json:"funding"`
-	Experiments []int    `json:"experiments"`
-	Communities []int    `json:"communities"`
-	Tags        []Tag    `json:"tags"`
-	Authors     []Author `json:"ds_authors"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Summary     string      `json:"summary"`
+	License     string      `json:"license"`
+	Funding     string      `json:"funding"`
+	Experiments []int       `json:"experiments"`
+	Communities []int       `json:"communities"`
+	Tags        []Tag       `json:"tags"`
+	Authors     []Author    `json:"ds_authors"`
+	Papers      []Paper     `json:"papers,omitempty"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
 }
 
 type Tag struct {
@@ -107,3 +109,9 @@ type CreateEntityStateRequest struct {
 	Current    bool        `json:"current"`
 	Attributes []Attribute `json:"attributes"`
 }
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
@@ -0,0 +1,312 @@
+package mcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SearchResultType is the kind of item a SearchResult refers to.
+type SearchResultType string
+
+const (
+	SearchResultFile       SearchResultType = "file"
+	SearchResultEntity     SearchResultType = "entity"
+	SearchResultActivity   SearchResultType = "activity"
+	SearchResultExperiment SearchResultType = "experiment"
+)
+
+// SearchResult is an item matching a search. Only the field for the result's Type is set.
+type SearchResult struct {
+	Type  SearchResultType
+	ID    int
+	Name  string
+	Score float64
+
+	File       *mcmodel.File
+	Entity     *mcmodel.Entity
+	Activity   *mcmodel.Activity
+	Experiment *mcmodel.Experiment
+}
+
+// searchResultResponse is the json for a single result from the search endpoint, eg
+// {"type": "entity", "score": 1.5, "item": {"id": 1,...}}.
+type searchResultResponse struct {
+	Type  SearchResultType `json:"type"`
+	Score float64          `json:"score"`
+	Item  json.RawMessage  `json:"item"`
+}
+
+// result decodes the item into a SearchResult. Results of a type the client doesn't know
+// about are skipped by returning nil.
+func (r searchResultResponse) result() (*SearchResult, error) {
+	result := &SearchResult{Type: r.Type, Score: r.Score}
+	var err error
+	switch r.Type {
+	case SearchResultFile:
+		result.File, err = decodeSearchItem[mcmodel.File](r.Item)
+		if err == nil {
+			result.ID, result.Name = result.File.ID, result.File.Name
+		}
+	case SearchResultEntity:
+		result.Entity, err = decodeSearchItem[mcmodel.Entity](r.Item)
+		if err == nil {
+			result.ID, result.Name = result.Entity.ID, result.Entity.Name
+		}
+	case SearchResultActivity:
+		result.Activity, err = decodeSearchItem[mcmodel.Activity](r.Item)
+		if err == nil {
+			result.ID, result.Name = result.Activity.ID, result.Activity.Name
+		}
+	case SearchResultExperiment:
+		result.Experiment, err = decodeSearchItem[mcmodel.Experiment](r.Item)
+		if err == nil {
+			result.ID, result.Name = result.Experiment.ID, result.Experiment.Name
+		}
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode %s search result: %w", r.Type, err)
+	}
+
+	return result, nil
+}
+
+// decodeSearchItem decodes the json for a search result's item.
+func decodeSearchItem[T any](item json.RawMessage) (*T, error) {
+	var v T
+	if err := json.Unmarshal(item, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Search finds the files, entities, activities and experiments in a project that match
+// query, best matches first. It uses the server's search. If the server doesn't support
+// search, Search falls back to building a SearchIndex from the project's files and metadata
+// and searching that. Use BuildSearchIndex directly to run several searches against the same
+// index.
+func (c *Client) Search(projectID int, query string) ([]SearchResult, error) {
+	op := c.startOperation("Search", projectIDAttr(projectID))
+	oc := op.client()
+
+	results, err := oc.serverSearch(projectID, query)
+	if !searchUnavailable(err) {
+		return results, op.end(err)
+	}
+
+	idx, err := oc.BuildSearchIndex(projectID)
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	return idx.Search(query), op.end(nil)
+}
+
+// serverSearch runs query with the server's search.
+func (c *Client) serverSearch(projectID int, query string) ([]SearchResult, error) {
+	responses, err := do[[]searchResultResponse](c, apiRequest{
+		op:     "ServerSearch",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/search", projectID),
+		query:  map[string]string{"search": query},
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(*responses))
+	for _, r := range *responses {
+		result, err := r.result()
+		if err != nil {
+			return nil, err
+		}
+
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	return results, nil
+}
+
+// searchUnavailable returns true if err shows that the server doesn't have a search endpoint.
+func searchUnavailable(err error) bool {
+	var apiErr *APIError
+	return errors.Is(err, ErrNotFound) ||
+		(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented)
+}
+
+// SearchIndex is an in-memory index of a project's files, entities, activities and
+// experiments. It matches the words in a query against their names, paths, descriptions and
+// attributes, and doesn't change when the project does.
+type SearchIndex struct {
+	docs []searchDoc
+}
+
+// searchDoc is an indexed item. name and text are lower case, and text includes name.
+type searchDoc struct {
+	result SearchResult
+	name   string
+	text   string
+}
+
+// BuildSearchIndex lists a project's files, entities, activities and experiments and indexes
+// them for searching.
+func (c *Client) BuildSearchIndex(projectID int) (*SearchIndex, error) {
+	op := c.startOperation("BuildSearchIndex", projectIDAttr(projectID))
+	idx, err := op.client().buildSearchIndex(projectID)
+	return idx, op.end(err)
+}
+
+// buildSearchIndex does the work for BuildSearchIndex.
+func (c *Client) buildSearchIndex(projectID int) (*SearchIndex, error) {
+	tree, err := c.GetProjectTree(projectID, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := collect(c.IterateEntities(projectID, ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := collect(c.IterateActivities(projectID, ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	experiments, err := collect(c.IterateExperiments(projectID, ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &SearchIndex{}
+
+	var visit func(t *FileTree)
+	visit = func(t *FileTree) {
+		for _, child := range t.Children {
+			visit(child)
+		}
+
+		if t.Path == "/" {
+			return
+		}
+
+		f := t.File
+		f.Path = t.Path
+		idx.add(SearchResult{Type: SearchResultFile, ID: f.ID, Name: f.Name, File: &f}, f.Path, f.MimeType)
+	}
+	visit(tree)
+
+	for i := range entities {
+		e := &entities[i]
+		text := []string{}
+		for _, state := range e.EntityStates {
+			text = append(text, attributesText(state.Attributes)...)
+		}
+		idx.add(SearchResult{Type: SearchResultEntity, ID: e.ID, Name: e.Name, Entity: e}, text...)
+	}
+
+	for i := range activities {
+		a := &activities[i]
+		idx.add(SearchResult{Type: SearchResultActivity, ID: a.ID, Name: a.Name, Activity: a}, attributesText(a.Attributes)...)
+	}
+
+	for i := range experiments {
+		e := &experiments[i]
+		idx.add(SearchResult{Type: SearchResultExperiment, ID: e.ID, Name: e.Name, Experiment: e}, e.Summary, e.Description)
+	}
+
+	return idx, nil
+}
+
+// add indexes result by its name and text.
+func (idx *SearchIndex) add(result SearchResult, text ...string) {
+	name := strings.ToLower(result.Name)
+	idx.docs = append(idx.docs, searchDoc{
+		result: result,
+		name:   name,
+		text:   strings.ToLower(strings.Join(append([]string{result.Name}, text...), " ")),
+	})
+}
+
+// Search returns the items that contain every word in query, ignoring case. Items are
+// scored by how many of the words are in their name, and sorted best first.
+func (idx *SearchIndex) Search(query string) []SearchResult {
+	terms := strings.Fields(strings.ToLower(query))
+	results := []SearchResult{}
+	if len(terms) == 0 {
+		return results
+	}
+
+	for _, doc := range idx.docs {
+		if score, ok := doc.score(terms); ok {
+			result := doc.result
+			result.Score = score
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	return results
+}
+
+// score returns the score for doc, and false if doc doesn't contain all the terms.
+func (doc searchDoc) score(terms []string) (float64, bool) {
+	score := 0.0
+	for _, term := range terms {
+		switch {
+		case strings.Contains(doc.name, term):
+			score += 2
+		case strings.Contains(doc.text, term):
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// attributesText returns the words to index for attrs. A value with a unit is indexed both
+// with and without a space, so that "500C" and "500 C" both match.
+func attributesText(attrs []mcmodel.Attribute) []string {
+	var text []string
+	for _, attr := range attrs {
+		text = append(text, attr.Name)
+		for _, v := range attr.AttributeValues {
+			value := attributeValueText(v)
+			text = append(text, value, v.Unit, value+v.Unit)
+		}
+	}
+	return text
+}
+
+// attributeValueText returns an attribute value as text. The server stores values as json
+// of the form {"value": ...}.
+func attributeValueText(v mcmodel.AttributeValue) string {
+	var wrapped struct {
+		Value any `json:"value"`
+	}
+
+	if err := json.Unmarshal([]byte(v.Val), &wrapped); err == nil && wrapped.Value != nil {
+		return fmt.Sprint(wrapped.Value)
+	}
+
+	return v.Val
+}
@@ -0,0 +1,84 @@
+package mcapi
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestClient_SearchUsesServer(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/search", `{"data": [
+		{"type": "entity", "score": 3.5, "item": {"id": 7, "name": "sample-1"}},
+		{"type": "dataset", "score": 2, "item": {"id": 9, "name": "skipped"}},
+		{"type": "file", "score": 1, "item": {"id": 2, "name": "a.txt", "path": "/a.txt"}}
+	]}`)
+
+	results, err := srv.client().Search(1, "annealed 500C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+
+	if r := results[0]; r.Type != SearchResultEntity || r.ID != 7 || r.Entity == nil || r.Score != 3.5 {
+		t.Errorf("unexpected entity result %+v", r)
+	}
+
+	if r := results[1]; r.Type != SearchResultFile || r.File == nil || r.File.Path != "/a.txt" {
+		t.Errorf("unexpected file result %+v", r)
+	}
+
+	if query, _ := url.ParseQuery(srv.received()[0].RawQuery); query.Get("search") != "annealed 500C" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestClient_SearchFallsBackToIndex(t *testing.T) {
+	// newTreeServer has no search endpoint, so it returns 404.
+	srv := newTreeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/entities", `{"data": [
+		{"id": 7, "name": "sample-1", "entity_states": [{"attributes": [
+			{"name": "anneal temperature", "attribute_values": [{"val": "{\"value\": 500}", "unit": "C"}]}]}]},
+		{"id": 8, "name": "sample-2", "entity_states": [{"attributes": [
+			{"name": "anneal temperature", "attribute_values": [{"val": "{\"value\": 300}", "unit": "C"}]}]}]}
+	]}`)
+	srv.handle(http.MethodGet, "/projects/1/activities", `{"data": [{"id": 3, "name": "Anneal"}]}`)
+	srv.handle(http.MethodGet, "/projects/1/experiments", `{"data": [
+		{"id": 4, "name": "Heat treatment", "description": "samples annealed at 500C and 300C"}
+	]}`)
+
+	results, err := srv.client().Search(1, "Anneal 500C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[SearchResultType]int)
+	for _, r := range results {
+		found[r.Type] = r.ID
+	}
+
+	// sample-2 was annealed at 300C, and the Anneal activity doesn't mention 500C.
+	expected := map[SearchResultType]int{SearchResultEntity: 7, SearchResultExperiment: 4}
+	if len(results) != 2 || !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected %v, got %+v", expected, results)
+	}
+}
+
+func TestSearchIndex_Search(t *testing.T) {
+	idx := &SearchIndex{}
+	idx.add(SearchResult{Type: SearchResultFile, ID: 1, Name: "b.dat"}, "/raw/b.dat")
+	idx.add(SearchResult{Type: SearchResultFile, ID: 2, Name: "raw"}, "/raw")
+
+	results := idx.Search("RAW")
+	if len(results) != 2 || results[0].ID != 2 || results[1].ID != 1 {
+		t.Errorf("expected name matches first, got %+v", results)
+	}
+
+	if results := idx.Search("  "); len(results) != 0 {
+		t.Errorf("empty query should match nothing, got %+v", results)
+	}
+}
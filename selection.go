@@ -0,0 +1,274 @@
+package mcapi
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+// SelectionBuilder builds a DatasetFileSelection from rules that are evaluated against a
+// project's files. For example, all the .h5 files under /runs except those in /runs/scratch:
+//
+//	b := NewSelectionBuilder().
+//		Include("/runs/**/*.h5").
+//		Exclude("/runs/scratch")
+//	preview, err := c.PreviewSelection(projectID, b)
+//	...
+//	_, err = c.UpdateDatasetFileSelection(projectID, datasetID, preview.Selection)
+//
+// A file is selected if it matches any include rule, or if there are no include rules, and
+// it matches no exclude rule and passes every size and date filter. The resulting selection
+// lists each selected file, so files added to the project later aren't picked up.
+type SelectionBuilder struct {
+	includes []pathMatcher
+	excludes []pathMatcher
+	filters  []func(f *mcmodel.File) bool
+	err      error
+}
+
+// pathMatcher reports whether a project path matches a rule.
+type pathMatcher func(p string) bool
+
+// NewSelectionBuilder returns a builder with no rules.
+func NewSelectionBuilder() *SelectionBuilder {
+	return &SelectionBuilder{}
+}
+
+// Include selects files matching the glob pattern. Patterns are matched using path.Match
+// against each element of a file's path, and "**" matches any number of directories. A
+// pattern that contains no "/", such as "*.h5", is matched against the file's name in any
+// directory. A pattern that matches a directory matches everything under it.
+func (b *SelectionBuilder) Include(pattern string) *SelectionBuilder {
+	b.includes = b.addGlob(b.includes, pattern)
+	return b
+}
+
+// Exclude removes files matching the glob pattern from the selection. Patterns follow the
+// same rules as Include.
+func (b *SelectionBuilder) Exclude(pattern string) *SelectionBuilder {
+	b.excludes = b.addGlob(b.excludes, pattern)
+	return b
+}
+
+// IncludeRegexp selects files whose project path matches the regular expression.
+func (b *SelectionBuilder) IncludeRegexp(expr string) *SelectionBuilder {
+	b.includes = b.addRegexp(b.includes, expr)
+	return b
+}
+
+// ExcludeRegexp removes files whose project path matches the regular expression.
+func (b *SelectionBuilder) ExcludeRegexp(expr string) *SelectionBuilder {
+	b.excludes = b.addRegexp(b.excludes, expr)
+	return b
+}
+
+// MinSize only selects files of at least size bytes.
+func (b *SelectionBuilder) MinSize(size int64) *SelectionBuilder {
+	b.filters = append(b.filters, func(f *mcmodel.File) bool { return int64(f.Size) >= size })
+	return b
+}
+
+// MaxSize only selects files of at most size bytes.
+func (b *SelectionBuilder) MaxSize(size int64) *SelectionBuilder {
+	b.filters = append(b.filters, func(f *mcmodel.File) bool { return int64(f.Size) <= size })
+	return b
+}
+
+// ModifiedAfter only selects files last changed after t.
+func (b *SelectionBuilder) ModifiedAfter(t time.Time) *SelectionBuilder {
+	b.filters = append(b.filters, func(f *mcmodel.File) bool { return f.UpdatedAt.After(t) })
+	return b
+}
+
+// ModifiedBefore only selects files last changed before t.
+func (b *SelectionBuilder) ModifiedBefore(t time.Time) *SelectionBuilder {
+	b.filters = append(b.filters, func(f *mcmodel.File) bool { return f.UpdatedAt.Before(t) })
+	return b
+}
+
+// addGlob appends a matcher for pattern to matchers. An invalid pattern is recorded in b.err
+// and reported by Evaluate.
+func (b *SelectionBuilder) addGlob(matchers []pathMatcher, pattern string) []pathMatcher {
+	if !strings.Contains(pattern, "/") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			b.setErr(fmt.Errorf("invalid pattern %q: %w", pattern, err))
+			return matchers
+		}
+
+		return append(matchers, func(p string) bool {
+			matched, _ := path.Match(pattern, path.Base(p))
+			return matched
+		})
+	}
+
+	segments := splitPath(pattern)
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			b.setErr(fmt.Errorf("invalid pattern %q: %w", pattern, err))
+			return matchers
+		}
+	}
+
+	return append(matchers, func(p string) bool {
+		// A match on a directory matches everything under it.
+		parts := splitPath(p)
+		for i := len(parts); i > 0; i-- {
+			if matchSegments(segments, parts[:i]) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// addRegexp appends a matcher for expr to matchers. An invalid expression is recorded in
+// b.err and reported by Evaluate.
+func (b *SelectionBuilder) addRegexp(matchers []pathMatcher, expr string) []pathMatcher {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		b.setErr(err)
+		return matchers
+	}
+	return append(matchers, re.MatchString)
+}
+
+// setErr records the first error made while building the rules.
+func (b *SelectionBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// selects returns true if the file at filePath is selected by the rules.
+func (b *SelectionBuilder) selects(filePath string, f *mcmodel.File) bool {
+	if len(b.includes) != 0 && !anyMatch(b.includes, filePath) {
+		return false
+	}
+
+	if anyMatch(b.excludes, filePath) {
+		return false
+	}
+
+	for _, filter := range b.filters {
+		if !filter(f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SelectionPreview is the result of evaluating a SelectionBuilder.
+type SelectionPreview struct {
+	// Selection lists each selected file. Pass it to UpdateDatasetFileSelection.
+	Selection DatasetFileSelection
+
+	// Files are the selected files, sorted by path, with their Path set.
+	Files []mcmodel.File
+
+	// TotalSize is the combined size of the selected files in bytes.
+	TotalSize int64
+}
+
+// Evaluate applies the rules to the files under tree, as returned by GetProjectTree.
+func (b *SelectionBuilder) Evaluate(tree *FileTree) (*SelectionPreview, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	preview := &SelectionPreview{
+		Selection: DatasetFileSelection{IncludeFiles: []string{}},
+	}
+
+	var visit func(t *FileTree)
+	visit = func(t *FileTree) {
+		if t.File.IsDir() {
+			for _, child := range t.Children {
+				visit(child)
+			}
+			return
+		}
+
+		if b.selects(t.Path, &t.File) {
+			f := t.File
+			f.Path = t.Path
+			preview.Files = append(preview.Files, f)
+			preview.TotalSize += int64(f.Size)
+		}
+	}
+	visit(tree)
+
+	sort.Slice(preview.Files, func(i, j int) bool { return preview.Files[i].Path < preview.Files[j].Path })
+	for _, f := range preview.Files {
+		preview.Selection.IncludeFiles = append(preview.Selection.IncludeFiles, f.Path)
+	}
+
+	return preview, nil
+}
+
+// PreviewSelection evaluates the rules in b against the project's files.
+func (c *Client) PreviewSelection(projectID int, b *SelectionBuilder) (*SelectionPreview, error) {
+	op := c.startOperation("PreviewSelection", projectIDAttr(projectID))
+	if b.err != nil {
+		return nil, op.end(b.err)
+	}
+
+	tree, err := op.client().GetProjectTree(projectID, "/")
+	if err != nil {
+		return nil, op.end(err)
+	}
+
+	preview, err := b.Evaluate(tree)
+	return preview, op.end(err)
+}
+
+// anyMatch returns true if any of matchers matches p.
+func anyMatch(matchers []pathMatcher, p string) bool {
+	for _, m := range matchers {
+		if m(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPath splits a cleaned project path into its elements. The root has no elements.
+func splitPath(p string) []string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// matchSegments returns true if the path elements in parts match the glob segments, where
+// a "**" segment matches zero or more elements.
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	if matched, _ := path.Match(segments[0], parts[0]); !matched {
+		return false
+	}
+
+	return matchSegments(segments[1:], parts[1:])
+}
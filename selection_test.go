@@ -0,0 +1,110 @@
+package mcapi
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+// testTree builds a FileTree from a list of file paths and sizes. Directories are created
+// as needed.
+func testTree(files map[string]uint64, updatedAt time.Time) *FileTree {
+	root := &FileTree{File: mcmodel.File{Name: "/", MimeType: "directory"}, Path: "/"}
+	dirs := map[string]*FileTree{"/": root}
+
+	var dir func(p string) *FileTree
+	dir = func(p string) *FileTree {
+		if d, ok := dirs[p]; ok {
+			return d
+		}
+		parent := dir(path.Dir(p))
+		d := &FileTree{File: mcmodel.File{Name: path.Base(p), MimeType: "directory"}, Path: p}
+		parent.Children = append(parent.Children, d)
+		dirs[p] = d
+		return d
+	}
+
+	for p, size := range files {
+		parent := dir(path.Dir(p))
+		parent.Children = append(parent.Children, &FileTree{
+			File: mcmodel.File{Name: path.Base(p), Size: size, UpdatedAt: updatedAt},
+			Path: p,
+		})
+	}
+	return root
+}
+
+func TestSelectionBuilder_Evaluate(t *testing.T) {
+	now := time.Now()
+	tree := testTree(map[string]uint64{
+		"/runs/r1/data.h5":      100,
+		"/runs/r1/notes.txt":    1,
+		"/runs/r2/deep/more.h5": 200,
+		"/runs/scratch/tmp.h5":  50,
+		"/other/x.h5":           10,
+	}, now)
+
+	tests := []struct {
+		name     string
+		builder  *SelectionBuilder
+		expected string
+		size     int64
+	}{
+		{"glob with exclude", NewSelectionBuilder().Include("/runs/**/*.h5").Exclude("/runs/scratch"),
+			"[/runs/r1/data.h5 /runs/r2/deep/more.h5]", 300},
+		{"name glob", NewSelectionBuilder().Include("*.h5").Exclude("/runs/scratch/**"),
+			"[/other/x.h5 /runs/r1/data.h5 /runs/r2/deep/more.h5]", 310},
+		{"directory", NewSelectionBuilder().Include("/runs/r1"), "[/runs/r1/data.h5 /runs/r1/notes.txt]", 101},
+		{"regexp", NewSelectionBuilder().IncludeRegexp(`^/runs/r\d/`).ExcludeRegexp(`\.txt$`),
+			"[/runs/r1/data.h5 /runs/r2/deep/more.h5]", 300},
+		{"size", NewSelectionBuilder().MinSize(50).MaxSize(150), "[/runs/r1/data.h5 /runs/scratch/tmp.h5]", 150},
+		{"date", NewSelectionBuilder().Include("*.h5").ModifiedAfter(now.Add(time.Hour)), "[]", 0},
+	}
+
+	for _, test := range tests {
+		preview, err := test.builder.Evaluate(tree)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if got := fmt.Sprint(preview.Selection.IncludeFiles); got != test.expected || preview.TotalSize != test.size {
+			t.Errorf("%s: got %s (%d bytes), expected %s (%d bytes)", test.name, got, preview.TotalSize, test.expected, test.size)
+		}
+
+		if len(preview.Files) != len(preview.Selection.IncludeFiles) {
+			t.Errorf("%s: preview files don't match selection", test.name)
+		}
+	}
+}
+
+func TestSelectionBuilder_InvalidRules(t *testing.T) {
+	if _, err := NewSelectionBuilder().Include("/runs/[").Evaluate(testTree(nil, time.Now())); err == nil {
+		t.Error("expected error for a bad glob")
+	}
+
+	if _, err := NewSelectionBuilder().IncludeRegexp("(").Evaluate(testTree(nil, time.Now())); err == nil {
+		t.Error("expected error for a bad regexp")
+	}
+}
+
+func TestClient_PreviewSelection(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/files", `{"data": [
+		{"id": 1, "name": "/", "path": "/", "mime_type": "directory", "directory_id": 1},
+		{"id": 10, "name": "a.h5", "directory_id": 1, "size": 5},
+		{"id": 11, "name": "b.txt", "directory_id": 1, "size": 7}]}`)
+
+	preview, err := srv.client().PreviewSelection(1, NewSelectionBuilder().Include("*.h5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(preview.Files) != 1 || preview.Files[0].ID != 10 || preview.TotalSize != 5 {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+}
@@ -0,0 +1,176 @@
+package mcapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName is the name the client's tracer and meter are registered under.
+const instrumentationName = "github.com/materials-commons/gomcapi"
+
+// telemetry holds the OpenTelemetry tracer and instruments used by the client. When the
+// client isn't given a TracerProvider or MeterProvider the no-op implementations are used.
+type telemetry struct {
+	tracer          trace.Tracer
+	requests        metric.Int64Counter
+	errors          metric.Int64Counter
+	duration        metric.Float64Histogram
+	uploadedBytes   metric.Int64Counter
+	downloadedBytes metric.Int64Counter
+}
+
+// newTelemetry creates the tracer and instruments from the given providers. Either
+// provider can be nil. Errors creating instruments are reported to the global OpenTelemetry
+// error handler, and the instrument that failed is replaced with a no-op.
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+
+	meter := mp.Meter(instrumentationName)
+	noopMeter := metricnoop.Meter{}
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+
+	var err error
+	t.requests, err = meter.Int64Counter("mcapi.client.requests",
+		metric.WithDescription("Number of API operations performed."))
+	if err != nil {
+		otel.Handle(err)
+		t.requests, _ = noopMeter.Int64Counter("")
+	}
+
+	t.errors, err = meter.Int64Counter("mcapi.client.errors",
+		metric.WithDescription("Number of API operations that failed."))
+	if err != nil {
+		otel.Handle(err)
+		t.errors, _ = noopMeter.Int64Counter("")
+	}
+
+	t.duration, err = meter.Float64Histogram("mcapi.client.duration",
+		metric.WithDescription("Duration of API operations."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+		t.duration, _ = noopMeter.Float64Histogram("")
+	}
+
+	t.uploadedBytes, err = meter.Int64Counter("mcapi.client.uploaded_bytes",
+		metric.WithDescription("Number of file bytes uploaded."),
+		metric.WithUnit("By"))
+	if err != nil {
+		otel.Handle(err)
+		t.uploadedBytes, _ = noopMeter.Int64Counter("")
+	}
+
+	t.downloadedBytes, err = meter.Int64Counter("mcapi.client.downloaded_bytes",
+		metric.WithDescription("Number of file bytes downloaded."),
+		metric.WithUnit("By"))
+	if err != nil {
+		otel.Handle(err)
+		t.downloadedBytes, _ = noopMeter.Int64Counter("")
+	}
+
+	return t
+}
+
+// operation tracks a single client API call, such as CreateEntity, from start to end. It
+// owns the span for the call and records the call's metrics when it ends.
+type operation struct {
+	c     *Client
+	name  string
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+}
+
+// startOperation starts a span named mcapi.<name> as a child of the client's context.
+// The returned operation must be ended with end.
+func (c *Client) startOperation(name string, attrs ...attribute.KeyValue) *operation {
+	ctx, span := c.telemetry.tracer.Start(c.context(), "mcapi."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	ctx = context.WithValue(ctx, operationKey{}, name)
+	return &operation{
+		c:     c,
+		name:  name,
+		ctx:   ctx,
+		span:  span,
+		start: time.Now(),
+	}
+}
+
+// client returns a client whose calls become children of this operation. It's used by
+// operations, such as DepositDataset, that are built out of other operations.
+func (op *operation) client() *Client {
+	return op.c.WithContext(op.ctx)
+}
+
+// addUploadedBytes records n bytes as uploaded by this operation.
+func (op *operation) addUploadedBytes(n int64) {
+	op.c.telemetry.uploadedBytes.Add(op.ctx, n, metric.WithAttributes(op.attr()))
+	op.span.SetAttributes(attribute.Int64("mcapi.uploaded_bytes", n))
+}
+
+// addDownloadedBytes records n bytes as downloaded by this operation.
+func (op *operation) addDownloadedBytes(n int64) {
+	op.c.telemetry.downloadedBytes.Add(op.ctx, n, metric.WithAttributes(op.attr()))
+	op.span.SetAttributes(attribute.Int64("mcapi.downloaded_bytes", n))
+}
+
+// end finishes the operation, recording err on the span and in the metrics. It returns
+// err so that it can wrap the error check at the end of a call.
+func (op *operation) end(err error) error {
+	attrs := []attribute.KeyValue{op.attr()}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		statusAttr := attribute.Int("http.response.status_code", apiErr.StatusCode)
+		attrs = append(attrs, statusAttr)
+		op.span.SetAttributes(statusAttr)
+	}
+
+	if err != nil {
+		op.span.RecordError(err)
+		op.span.SetStatus(codes.Error, err.Error())
+		op.c.telemetry.errors.Add(op.ctx, 1, metric.WithAttributes(attrs...))
+	}
+
+	op.c.telemetry.requests.Add(op.ctx, 1, metric.WithAttributes(attrs...))
+	op.c.telemetry.duration.Record(op.ctx, time.Since(op.start).Seconds(), metric.WithAttributes(attrs...))
+	op.span.End()
+
+	return err
+}
+
+// attr returns the attribute identifying the operation in metrics.
+func (op *operation) attr() attribute.KeyValue {
+	return attribute.String("mcapi.operation", op.name)
+}
+
+// projectIDAttr returns the span attribute for a project ID.
+func projectIDAttr(id int) attribute.KeyValue {
+	return attribute.Int("mcapi.project_id", id)
+}
+
+// datasetIDAttr returns the span attribute for a dataset ID.
+func datasetIDAttr(id int) attribute.KeyValue {
+	return attribute.Int("mcapi.dataset_id", id)
+}
+
+// communityIDAttr returns the span attribute for a community ID.
+func communityIDAttr(id int) attribute.KeyValue {
+	return attribute.Int("mcapi.community_id", id)
+}
@@ -0,0 +1,133 @@
+package mcapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTelemetryTestClient(t *testing.T, mux *http.ServeMux) (*Client, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	c := NewClient(&ClientArgs{
+		BaseURL:        srv.URL,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	return c, recorder, reader
+}
+
+func TestClient_TracesOperation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/5/datasets/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 7}}`)
+	})
+	c, recorder, reader := newTelemetryTestClient(t, mux)
+
+	if _, err := c.GetDataset(5, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "mcapi.GetDataset" {
+		t.Errorf("unexpected span name %q", spans[0].Name())
+	}
+
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	if v, _ := attrs.Value("mcapi.project_id"); v.AsInt64() != 5 {
+		t.Errorf("expected project id attribute 5, got %v", v)
+	}
+	if v, _ := attrs.Value("mcapi.dataset_id"); v.AsInt64() != 7 {
+		t.Errorf("expected dataset id attribute 7, got %v", v)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "mcapi.client.requests" {
+				found = true
+				sum := m.Data.(metricdata.Sum[int64])
+				if sum.DataPoints[0].Value != 1 {
+					t.Errorf("expected 1 request, got %d", sum.DataPoints[0].Value)
+				}
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("mcapi.client.requests metric not recorded")
+	}
+}
+
+func TestClient_DepositDatasetSpans(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /projects/1/datasets", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 2, "uuid": "ds-uuid"}}`)
+	})
+	mux.HandleFunc("POST /directories/by-path", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 3, "path": "/ds-uuid", "mime_type": "directory"}}`)
+	})
+	mux.HandleFunc("POST /projects/1/files/3/upload", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": [{"id": 4, "name": "f.txt"}]}`)
+	})
+	mux.HandleFunc("PUT /projects/1/datasets/2/change_file_selection", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data": {"id": 2, "uuid": "ds-uuid"}}`)
+	})
+	c, recorder, _ := newTelemetryTestClient(t, mux)
+
+	filePath := filepath.Join(t.TempDir(), "f.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	req := DepositDatasetRequest{Files: []DatasetFileUpload{{File: filePath}}}
+	if _, err := c.DepositDataset(1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	var root sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "mcapi.DepositDataset" {
+			root = span
+		}
+	}
+
+	if root == nil {
+		t.Fatal("no mcapi.DepositDataset span")
+	}
+
+	for _, span := range recorder.Ended() {
+		if span == root {
+			continue
+		}
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("span %s is not part of the DepositDataset trace", span.Name())
+		}
+	}
+
+	if n := len(recorder.Ended()); n != 7 {
+		t.Errorf("expected 7 spans, got %d", n)
+	}
+}
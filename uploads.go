@@ -0,0 +1,212 @@
+package mcapi
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+// UploadPolicy decides what happens when a file is uploaded to a path where a file already
+// exists in the project.
+type UploadPolicy int
+
+const (
+	// UploadNewVersion always uploads the file. The existing file is kept as a previous
+	// version. This is what UploadFile and UploadFileTo do.
+	UploadNewVersion UploadPolicy = iota
+
+	// UploadSkipUnchanged skips the upload when the existing file has the same checksum as
+	// the local file. Otherwise the file is uploaded as a new version.
+	UploadSkipUnchanged
+
+	// UploadOverwrite skips the upload when the existing file has the same checksum as the
+	// local file. Otherwise the file is uploaded and every previous version of it is
+	// deleted, so that no history is kept.
+	UploadOverwrite
+)
+
+// UploadOptions control uploads made with UploadFileWithOptions and UploadFiles.
+type UploadOptions struct {
+	Policy UploadPolicy
+}
+
+// FileUpload is a local file to upload and the project directory to upload it to.
+type FileUpload struct {
+	LocalPath  string
+	ProjectDir string
+}
+
+// UploadResult describes the outcome of uploading a single file.
+type UploadResult struct {
+	LocalPath   string
+	ProjectPath string
+
+	// File is the uploaded file, or the existing file if the upload was skipped.
+	File *mcmodel.File
+
+	// Skipped is true if the upload was skipped because the file was unchanged.
+	Skipped bool
+
+	// Checksum is the md5 checksum of the local file. It's only computed when the policy
+	// needs it.
+	Checksum string
+}
+
+// UploadFileWithOptions uploads the local file at filePath into the project directory
+// projectDir, applying opts.Policy when a file with the same name already exists there. The
+// directory is created if it doesn't exist.
+func (c *Client) UploadFileWithOptions(projectID int, filePath, projectDir string, opts UploadOptions) (*UploadResult, error) {
+	op := c.startOperation("UploadFileWithOptions", projectIDAttr(projectID))
+	result, err := newUploader(op.client(), projectID, opts).upload(filePath, projectDir)
+	return result, op.end(err)
+}
+
+// UploadFiles uploads each of the files, applying opts to each one. It stops at the first
+// error, returning the results for the files that were handled before it. Check the Skipped
+// field of each result to see which files were unchanged.
+func (c *Client) UploadFiles(projectID int, files []FileUpload, opts UploadOptions) ([]UploadResult, error) {
+	op := c.startOperation("UploadFiles", projectIDAttr(projectID))
+	u := newUploader(op.client(), projectID, opts)
+	results := make([]UploadResult, 0, len(files))
+	for _, f := range files {
+		result, err := u.upload(f.LocalPath, f.ProjectDir)
+		if err != nil {
+			return results, op.end(fmt.Errorf("unable to upload %s: %w", f.LocalPath, err))
+		}
+		results = append(results, *result)
+	}
+	return results, op.end(nil)
+}
+
+// uploader uploads files into a project, applying an UploadPolicy. It remembers the IDs of
+// the directories it has created so that uploading many files into the same directories
+// doesn't look each one up again.
+type uploader struct {
+	c         *Client
+	projectID int
+	policy    UploadPolicy
+	dirs      map[string]int
+}
+
+// newUploader creates an uploader for the project.
+func newUploader(c *Client, projectID int, opts UploadOptions) *uploader {
+	return &uploader{c: c, projectID: projectID, policy: opts.Policy, dirs: make(map[string]int)}
+}
+
+// directoryID returns the ID of the project directory at projectDir, creating it if it
+// doesn't exist.
+func (u *uploader) directoryID(projectDir string) (int, error) {
+	projectDir = path.Clean("/" + projectDir)
+	if id, ok := u.dirs[projectDir]; ok {
+		return id, nil
+	}
+
+	dir, err := u.c.CreateDirectoryByPath(u.projectID, projectDir)
+	if err != nil {
+		return 0, err
+	}
+
+	u.dirs[projectDir] = dir.ID
+	return dir.ID, nil
+}
+
+// upload uploads the local file at filePath into projectDir, applying the policy.
+func (u *uploader) upload(filePath, projectDir string) (*UploadResult, error) {
+	projectDir = path.Clean("/" + projectDir)
+	result := &UploadResult{
+		LocalPath:   filePath,
+		ProjectPath: path.Join(projectDir, filepath.Base(filePath)),
+	}
+
+	var existing *mcmodel.File
+	if u.policy != UploadNewVersion {
+		checksum, err := fileChecksum(filePath)
+		if err != nil {
+			return nil, err
+		}
+		result.Checksum = checksum
+
+		existing, err = u.c.GetFileByPath(u.projectID, result.ProjectPath)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			existing = nil
+		case err != nil:
+			return nil, err
+		case existing.IsDir():
+			return nil, fmt.Errorf("%s is a directory in the project", result.ProjectPath)
+		}
+
+		if existing != nil && existing.Checksum == checksum {
+			result.File = existing
+			result.Skipped = true
+			return result, nil
+		}
+	}
+
+	dirID, err := u.directoryID(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := u.c.UploadFile(u.projectID, dirID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	result.File = file
+
+	if u.policy == UploadOverwrite && existing != nil {
+		// Delete the old versions only after the new one is safely uploaded.
+		if err := u.deletePreviousVersions(file); err != nil {
+			return result, fmt.Errorf("uploaded %s but couldn't delete the previous versions: %w", result.ProjectPath, err)
+		}
+	}
+
+	return result, nil
+}
+
+// deletePreviousVersions deletes every version of file other than file itself.
+func (u *uploader) deletePreviousVersions(file *mcmodel.File) error {
+	versions, err := u.c.ListFileVersions(u.projectID, IDRef(file.ID))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, version := range versions {
+		if version.ID == file.ID {
+			continue
+		}
+
+		if err := u.c.DeleteFile(u.projectID, IDRef(version.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("version %d: %w", version.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileChecksum returns the hex encoded md5 checksum of the file at filePath. This matches the
+// checksum the server stores for uploaded files.
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
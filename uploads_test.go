@@ -0,0 +1,136 @@
+package mcapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClient_UploadSkipUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	same := filepath.Join(dir, "same.txt")
+	changed := filepath.Join(dir, "changed.txt")
+	if err := os.WriteFile(same, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(changed, []byte("new contents"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	handleByPath(srv, map[string]string{
+		// md5 of "hello"
+		"/raw/same.txt":    `{"id": 10, "name": "same.txt", "checksum": "5d41402abc4b2a76b9719d911017c592"}`,
+		"/raw/changed.txt": `{"id": 11, "name": "changed.txt", "checksum": "0123456789abcdef0123456789abcdef"}`,
+	})
+	srv.handle(http.MethodPost, "/directories/by-path", `{"data": {"id": 2, "mime_type": "directory"}}`)
+	srv.handle(http.MethodPost, "/projects/1/files/2/upload", `{"data": [{"id": 12, "name": "changed.txt"}]}`)
+	srv.handle(http.MethodDelete, "/files/11", `{}`)
+
+	results, err := srv.client().UploadFiles(1, []FileUpload{
+		{LocalPath: same, ProjectDir: "/raw"},
+		{LocalPath: changed, ProjectDir: "/raw"},
+	}, UploadOptions{Policy: UploadSkipUnchanged})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || !results[0].Skipped || results[1].Skipped {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	if results[0].File.ID != 10 || results[1].File.ID != 12 {
+		t.Errorf("unexpected files %+v, %+v", results[0].File, results[1].File)
+	}
+
+	for _, req := range srv.received() {
+		if req.Method == http.MethodDelete {
+			t.Errorf("UploadSkipUnchanged should not delete, got %s %s", req.Method, req.Path)
+		}
+	}
+}
+
+func TestClient_UploadOverwrite(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(local, []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	handleByPath(srv, map[string]string{
+		"/data.txt": `{"id": 10, "name": "data.txt", "checksum": "0123456789abcdef0123456789abcdef"}`,
+	})
+	srv.handle(http.MethodPost, "/directories/by-path", `{"data": {"id": 1, "mime_type": "directory"}}`)
+	srv.handle(http.MethodPost, "/projects/1/files/1/upload", `{"data": [{"id": 12, "name": "data.txt"}]}`)
+	srv.handle(http.MethodGet, "/projects/1/files/12/versions", `{"data": [
+		{"id": 12, "name": "data.txt", "current": true},
+		{"id": 10, "name": "data.txt"},
+		{"id": 7, "name": "data.txt"}]}`)
+	srv.handle(http.MethodDelete, "/files/10", `{}`)
+	srv.handle(http.MethodDelete, "/files/7", `{}`)
+
+	result, err := srv.client().UploadFileWithOptions(1, local, "", UploadOptions{Policy: UploadOverwrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Skipped || result.File.ID != 12 || result.ProjectPath != "/data.txt" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	var deleted []string
+	for _, r := range srv.received() {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.Path)
+		}
+	}
+
+	if expected := []string{"/files/10", "/files/7"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("expected every previous version to be deleted, deleted %v", deleted)
+	}
+}
+
+func TestClient_UploadRelativeProjectDir(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(local, []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	handleByPath(srv, map[string]string{
+		// md5 of "v2"
+		"/raw/data.txt": `{"id": 10, "name": "data.txt", "checksum": "1b267619c4812cc46ee281747884ca50"}`,
+	})
+
+	result, err := srv.client().UploadFileWithOptions(1, local, "raw/", UploadOptions{Policy: UploadSkipUnchanged})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Skipped || result.ProjectPath != "/raw/data.txt" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestClient_UploadNewFile(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "new.txt")
+	if err := os.WriteFile(local, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newFakeServer(t)
+	handleByPath(srv, map[string]string{})
+	srv.handle(http.MethodPost, "/directories/by-path", `{"data": {"id": 1, "mime_type": "directory"}}`)
+	srv.handle(http.MethodPost, "/projects/1/files/1/upload", `{"data": [{"id": 5, "name": "new.txt"}]}`)
+
+	result, err := srv.client().UploadFileWithOptions(1, local, "/", UploadOptions{Policy: UploadOverwrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Skipped || result.File.ID != 5 || result.Checksum != "8d777f385d3dfec8815d20f7496026dc" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,43 @@
+package mcapi
+
+import (
+	"net/http"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+)
+
+// GetCurrentUser returns the user the client's API key belongs to.
+func (c *Client) GetCurrentUser() (*mcmodel.User, error) {
+	return do[mcmodel.User](c, apiRequest{
+		op:     "GetCurrentUser",
+		method: http.MethodGet,
+		path:   "/user",
+	})
+}
+
+// GetUserByEmail looks up a user by their email address.
+func (c *Client) GetUserByEmail(email string) (*mcmodel.User, error) {
+	return do[mcmodel.User](c, apiRequest{
+		op:     "GetUserByEmail",
+		method: http.MethodGet,
+		path:   apiPath("/users/by-email/%s", email),
+	})
+}
+
+// CheckAuth makes a cheap call to the server to check that the client's API key is valid. If
+// the key is missing, invalid or has expired then the returned error matches ErrUnauthorized.
+// Other errors mean the server couldn't be reached or had a problem.
+func (c *Client) CheckAuth() error {
+	_, err := c.GetCurrentUser()
+	return err
+}
+
+// NewCheckedClient creates a client like NewClient, then checks the API key with CheckAuth so
+// that tools can fail at startup rather than part way through a long job.
+func NewCheckedClient(args *ClientArgs) (*Client, error) {
+	c := NewClient(args)
+	if err := c.CheckAuth(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
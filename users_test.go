@@ -0,0 +1,53 @@
+package mcapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetCurrentUser(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/user", `{"data": {"id": 3, "name": "Jo", "email": "jo@lab.edu"}}`)
+
+	c, err := NewCheckedClient(&ClientArgs{BaseURL: srv.URL, APIKey: "good"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := c.GetCurrentUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.ID != 3 || user.Email != "jo@lab.edu" {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
+
+func TestNewCheckedClient_InvalidKey(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handleFunc(http.MethodGet, "/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "Unauthenticated."}`))
+	})
+
+	_, err := NewCheckedClient(&ClientArgs{BaseURL: srv.URL, APIKey: "expired"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	unauthorized := &APIError{StatusCode: http.StatusUnauthorized}
+	forbidden := &APIError{StatusCode: http.StatusForbidden}
+
+	if !errors.Is(unauthorized, ErrUnauthorized) || errors.Is(unauthorized, ErrForbidden) {
+		t.Errorf("401 should only match ErrUnauthorized")
+	}
+
+	if !errors.Is(forbidden, ErrForbidden) || errors.Is(forbidden, ErrUnauthorized) {
+		t.Errorf("403 should only match ErrForbidden")
+	}
+}
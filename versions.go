@@ -0,0 +1,85 @@
+package mcapi
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/materials-commons/hydra/pkg/mcdb/mcmodel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Materials Commons keeps the previous versions of a file when a file with the same name is
+// uploaded to the same directory. Each version is a mcmodel.File with its own ID, and the
+// version that is shown in the project has Current set.
+
+// ListFileVersions lists every version of a file, including the current one.
+func (c *Client) ListFileVersions(projectID int, file FileRef) ([]mcmodel.File, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := do[[]mcmodel.File](c, apiRequest{
+		op:     "ListFileVersions",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/files/%d/versions", projectID, fileID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *versions, nil
+}
+
+// GetFileVersion retrieves a single version of a file.
+func (c *Client) GetFileVersion(projectID int, file FileRef, versionID int) (*mcmodel.File, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[mcmodel.File](c, apiRequest{
+		op:     "GetFileVersion",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/files/%d/versions/%d", projectID, fileID, versionID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), fileVersionAttr(versionID)},
+	})
+}
+
+// DownloadFileVersion writes the contents of a version of a file to w, returning the number
+// of bytes written.
+func (c *Client) DownloadFileVersion(projectID int, file FileRef, versionID int, w io.Writer) (int64, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.download(apiRequest{
+		op:     "DownloadFileVersion",
+		method: http.MethodGet,
+		path:   apiPath("/projects/%d/files/%d/versions/%d/download", projectID, fileID, versionID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), fileVersionAttr(versionID)},
+	}, w)
+}
+
+// SetCurrentFileVersion makes a previous version of a file the current one, for example to
+// undo an accidental overwrite. The version that was current is kept as a previous version.
+// It returns the newly current version.
+func (c *Client) SetCurrentFileVersion(projectID int, file FileRef, versionID int) (*mcmodel.File, error) {
+	fileID, err := c.resolve(projectID, file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[mcmodel.File](c, apiRequest{
+		op:     "SetCurrentFileVersion",
+		method: http.MethodPut,
+		path:   apiPath("/projects/%d/files/%d/versions/%d/make-current", projectID, fileID, versionID),
+		attrs:  []attribute.KeyValue{projectIDAttr(projectID), fileVersionAttr(versionID)},
+	})
+}
+
+// fileVersionAttr returns the span attribute for a file version ID.
+func fileVersionAttr(id int) attribute.KeyValue {
+	return attribute.Int("mcapi.file_version_id", id)
+}
@@ -0,0 +1,64 @@
+package mcapi
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_FileVersions(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle(http.MethodGet, "/projects/1/files/10/versions", `{"data": [
+		{"id": 10, "name": "raw.h5", "current": true},
+		{"id": 8, "name": "raw.h5", "current": false}]}`)
+	srv.handle(http.MethodGet, "/projects/1/files/10/versions/8", `{"data": {"id": 8, "name": "raw.h5"}}`)
+	srv.handle(http.MethodPut, "/projects/1/files/10/versions/8/make-current", `{"data": {"id": 8, "current": true}}`)
+	srv.handleFunc(http.MethodGet, "/projects/1/files/10/versions/8/download", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("old contents"))
+	})
+	c := srv.client()
+
+	versions, err := c.ListFileVersions(1, IDRef(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(versions) != 2 || versions[1].ID != 8 {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+
+	if v, err := c.GetFileVersion(1, IDRef(10), 8); err != nil || v.ID != 8 {
+		t.Errorf("GetFileVersion returned %+v, %v", v, err)
+	}
+
+	var buf bytes.Buffer
+	n, err := c.DownloadFileVersion(1, IDRef(10), 8, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "old contents" || n != int64(buf.Len()) {
+		t.Errorf("unexpected download %q (%d bytes)", buf.String(), n)
+	}
+
+	if v, err := c.SetCurrentFileVersion(1, IDRef(10), 8); err != nil || !v.Current {
+		t.Errorf("SetCurrentFileVersion returned %+v, %v", v, err)
+	}
+}
+
+func TestClient_DownloadError(t *testing.T) {
+	srv := newFakeServer(t)
+
+	var buf bytes.Buffer
+	_, err := srv.client().DownloadFileVersion(1, IDRef(10), 9, &buf)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 APIError, got %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("error body should not be written to the destination")
+	}
+}